@@ -0,0 +1,32 @@
+package database
+
+import (
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/models"
+	"github.com/volatiletech/sqlboiler/v4/queries/qm"
+)
+
+// GetUserFromCookie get user from SESSDATA cookie
+func (h *DbHelper) GetUserFromCookie(sessdata string) (*models.User, error) {
+	return models.Users(
+		qm.InnerJoin("cookie_sessions ON cookie_sessions.uid = users.uid"),
+		qm.Where("cookie_sessions.key = ?", h.hashKey(sessdata)),
+	).One(h.ctx, h.db)
+}
+
+// InsertOrUpdateCookieSession insert or update SESSDATA cookie of user
+func (h *DbHelper) InsertOrUpdateCookieSession(sessdata string, uid int64) error {
+	now := time.Now().UTC()
+	_, err := h.db.ExecContext(h.ctx, `
+INSERT INTO cookie_sessions (key, uid, created_at, updated_at) VALUES ($1, $2, $3, $3)
+ON CONFLICT (key) DO UPDATE SET uid = EXCLUDED.uid, updated_at = EXCLUDED.updated_at`,
+		h.hashKey(sessdata), uid, now)
+	return err
+}
+
+// DeleteCookieSession delete SESSDATA cookie
+func (h *DbHelper) DeleteCookieSession(sessdata string) error {
+	_, err := h.db.ExecContext(h.ctx, `DELETE FROM cookie_sessions WHERE key = $1`, h.hashKey(sessdata))
+	return err
+}
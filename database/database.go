@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,16 +23,26 @@ type Config struct {
 	DBName   string
 	Port     int
 	Debug    bool
+
+	// KeySecret HMAC secret of access keys stored at rest
+	KeySecret []byte
 }
 
 // DbHelper database helper
 type DbHelper struct {
-	ctx context.Context
-	db  *sql.DB
+	ctx        context.Context
+	db         *sql.DB
+	dsn        string
+	instanceID string
+	keySecret  []byte
 }
 
 // NewDBConnection new database connection
 func NewDBConnection(c *Config) (*DbHelper, error) {
+	if len(c.KeySecret) == 0 {
+		return nil, errors.New("access key secret is empty, set accessKeyHash.secret")
+	}
+
 	boil.DebugMode = c.Debug
 	// connect to database
 	dsn := fmt.Sprintf(
@@ -49,22 +60,37 @@ func NewDBConnection(c *Config) (*DbHelper, error) {
 	}
 	n, err := migrate.Exec(db, "postgres", migrations, migrate.Up)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 	fmt.Printf("Applied %d migrations!\n", n)
 
-	return &DbHelper{ctx: context.Background(), db: db}, err
+	h := &DbHelper{ctx: context.Background(), db: db, dsn: dsn, instanceID: newInstanceID(), keySecret: c.KeySecret}
+	hashed, err := h.HashPlainKeysOnce()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if hashed > 0 {
+		fmt.Printf("Hashed %d plain access keys!\n", hashed)
+	}
+	return h, nil
+}
+
+// Close close database connections
+func (h *DbHelper) Close() error {
+	return h.db.Close()
 }
 
 // GetKey get access key data
 func (h *DbHelper) GetKey(key string) (*models.AccessKey, error) {
-	return models.AccessKeys(models.AccessKeyWhere.Key.EQ(key)).One(h.ctx, h.db)
+	return models.AccessKeys(models.AccessKeyWhere.Key.EQ(h.hashKey(key))).One(h.ctx, h.db)
 }
 
 // InsertOrUpdateKey insert or update access key data
 func (h *DbHelper) InsertOrUpdateKey(key string, uid int64, clientType string) error {
 	var accessKeyTable models.AccessKey
-	accessKeyTable.Key = key
+	accessKeyTable.Key = h.hashKey(key)
 	accessKeyTable.UID = uid
 	accessKeyTable.ClientType = clientType
 	return accessKeyTable.Upsert(h.ctx, h.db, true, []string{"key"}, boil.Whitelist("client_type", "updated_at"), boil.Infer())
@@ -85,7 +111,7 @@ func (h *DbHelper) GetUser(uid int64) (*models.User, error) {
 func (h *DbHelper) GetUserFromKey(key string) (*models.User, error) {
 	return models.Users(
 		qm.InnerJoin("access_keys ON access_keys.uid = users.uid"),
-		models.AccessKeyWhere.Key.EQ(key),
+		models.AccessKeyWhere.Key.EQ(h.hashKey(key)),
 	).One(h.ctx, h.db)
 }
 
@@ -103,6 +129,9 @@ func (h *DbHelper) DeleteUser(uid int64) (int64, error) {
 	if _, err := models.AccessKeys(models.AccessKeyWhere.UID.EQ(uid)).DeleteAll(h.ctx, h.db); err != nil {
 		return -1, err
 	}
+	if _, err := h.db.ExecContext(h.ctx, `DELETE FROM cookie_sessions WHERE uid = $1`, uid); err != nil {
+		return -1, err
+	}
 	return models.Users(models.UserWhere.UID.EQ(uid)).DeleteAll(h.ctx, h.db)
 }
 
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+)
+
+const (
+	// plainKeyLength length of raw bilibili access key, hashed key is 64
+	plainKeyLength = 32
+	// hashPlainKeysTask maintenance task marker of plain key conversion
+	hashPlainKeysTask = "hash_plain_keys"
+)
+
+// hashKey access keys are only compared, never read back
+func (h *DbHelper) hashKey(key string) string {
+	mac := hmac.New(sha256.New, h.keySecret)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashPlainKeysOnce convert plain keys at first startup after upgrade, skipped once marked done
+func (h *DbHelper) HashPlainKeysOnce() (int64, error) {
+	var name string
+	err := h.db.QueryRowContext(h.ctx, `SELECT name FROM maintenance_tasks WHERE name = $1`, hashPlainKeysTask).Scan(&name)
+	if err == nil {
+		return 0, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	n, err := h.HashPlainKeys()
+	if err != nil {
+		return n, err
+	}
+	_, err = h.db.ExecContext(h.ctx, `INSERT INTO maintenance_tasks (name, done_at) VALUES ($1, NOW()) ON CONFLICT (name) DO NOTHING`, hashPlainKeysTask)
+	return n, err
+}
+
+// HashPlainKeys conversion of access keys stored before hashing,
+// plain keys are never matched after upgrade and expire by retention if not converted
+func (h *DbHelper) HashPlainKeys() (int64, error) {
+	var total int64
+	for {
+		n, err := h.hashPlainKeysBatch()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < defaultRetentionBatchSize {
+			break
+		}
+	}
+
+	// short lived, drop instead of converting
+	res, err := h.db.ExecContext(h.ctx, `DELETE FROM auth_caches WHERE LENGTH(key) = $1`, plainKeyLength)
+	if err != nil {
+		return total, err
+	}
+	if _, err := res.RowsAffected(); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+func (h *DbHelper) hashPlainKeysBatch() (int64, error) {
+	tx, err := h.db.BeginTx(h.ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(h.ctx, `SELECT key FROM access_keys WHERE LENGTH(key) = $1 LIMIT $2 FOR UPDATE`, plainKeyLength, defaultRetentionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		// user may have verified again after upgrade, keep the newer hashed row
+		if _, err := tx.ExecContext(h.ctx, `INSERT INTO access_keys (key, uid, client_type, created_at, updated_at)
+			SELECT $1, uid, client_type, created_at, updated_at FROM access_keys WHERE key = $2
+			ON CONFLICT (key) DO NOTHING`, h.hashKey(key), key); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(h.ctx, `DELETE FROM access_keys WHERE key = $1`, key); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(keys)), tx.Commit()
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// LeaderLockCleanup advisory lock id of background cleanup jobs
+const LeaderLockCleanup int64 = 0x62696c69726f616d
+
+// LeaderElector leader election by postgres session level advisory lock
+type LeaderElector struct {
+	h      *DbHelper
+	lockID int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewLeaderElector new leader elector with advisory lock id
+func (h *DbHelper) NewLeaderElector(lockID int64) *LeaderElector {
+	return &LeaderElector{
+		h:      h,
+		lockID: lockID,
+	}
+}
+
+// IsLeader try to become or stay leader,
+// the lock is released by postgres when leader connection dies so other instances can take over
+func (e *LeaderElector) IsLeader() (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		if err := e.conn.PingContext(e.h.ctx); err == nil {
+			return true, nil
+		}
+		// connection lost, so is the lock
+		e.conn.Close()
+		e.conn = nil
+	}
+
+	conn, err := e.h.db.Conn(e.h.ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(e.h.ctx, `SELECT pg_try_advisory_lock($1)`, e.lockID).Scan(&locked); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+
+	e.conn = conn
+	return true, nil
+}
+
+// Release give up leadership
+func (e *LeaderElector) Release() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn == nil {
+		return nil
+	}
+	_, err := e.conn.ExecContext(e.h.ctx, `SELECT pg_advisory_unlock($1)`, e.lockID)
+	e.conn.Close()
+	e.conn = nil
+	return err
+}
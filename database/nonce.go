@@ -0,0 +1,35 @@
+package database
+
+import "time"
+
+// InsertNonce return false if nonce is used and not expired
+func (h *DbHelper) InsertNonce(key string, expiresAt time.Time) (bool, error) {
+	result, err := h.db.ExecContext(h.ctx, `
+INSERT INTO request_nonces AS n (key, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (key) DO UPDATE SET expires_at = EXCLUDED.expires_at
+WHERE n.expires_at <= $3`, key, expiresAt.UTC(), time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// DeleteNonce delete nonce so the sign can be used again
+func (h *DbHelper) DeleteNonce(key string) error {
+	_, err := h.db.ExecContext(h.ctx, `DELETE FROM request_nonces WHERE key = $1`, key)
+	return err
+}
+
+// CleanupNonces cleanup expired nonces
+func (h *DbHelper) CleanupNonces() (int64, error) {
+	result, err := h.db.ExecContext(h.ctx, `DELETE FROM request_nonces WHERE expires_at <= $1`, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
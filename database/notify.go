@@ -0,0 +1,171 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/models"
+	"github.com/lib/pq"
+)
+
+const invalidationChannel = "biliroaming_invalidation"
+
+// InvalidationType 缓存失效事件种类
+type InvalidationType string
+
+// InvalidationType
+const (
+	InvalidationUserReauth    InvalidationType = "user_reauth"
+	InvalidationBanChange     InvalidationType = "ban_change"
+	InvalidationUserForget    InvalidationType = "user_forget"
+	InvalidationSessionRevoke InvalidationType = "session_revoke"
+	InvalidationEpisodeCache  InvalidationType = "episode_cache"
+	InvalidationSeasonCache   InvalidationType = "season_cache"
+	InvalidationConfigChange  InvalidationType = "config_change"
+)
+
+// InvalidationEvent cache invalidation event published by LISTEN/NOTIFY
+type InvalidationEvent struct {
+	Type      InvalidationType `json:"type"`
+	Origin    string           `json:"origin"`
+	UID       int64            `json:"uid,omitempty"`
+	EpisodeID int64            `json:"episode_id,omitempty"`
+	SeasonID  int64            `json:"season_id,omitempty"`
+}
+
+// InvalidationHandler handle invalidation events, receive nil after reconnected
+// which means events may be missed and all local entries should be evicted
+type InvalidationHandler func(event *InvalidationEvent)
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// InstanceID random id of current process
+func (h *DbHelper) InstanceID() string {
+	return h.instanceID
+}
+
+// PublishInvalidation publish invalidation event to all instances
+func (h *DbHelper) PublishInvalidation(event *InvalidationEvent) error {
+	event.Origin = h.instanceID
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = h.db.ExecContext(h.ctx, `SELECT pg_notify($1, $2)`, invalidationChannel, string(payload))
+	return err
+}
+
+// ListenInvalidation subscribe invalidation events from other instances
+func (h *DbHelper) ListenInvalidation(handler InvalidationHandler, onError func(error)) (*pq.Listener, error) {
+	listener := pq.NewListener(h.dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			onError(err)
+		}
+		if ev == pq.ListenerEventReconnected {
+			// notifications during failover are lost
+			handler(nil)
+		}
+	})
+	if err := listener.Listen(invalidationChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(90 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case n, ok := <-listener.Notify:
+				if !ok {
+					return
+				}
+				if n == nil {
+					continue
+				}
+				event := &InvalidationEvent{}
+				if err := json.Unmarshal([]byte(n.Extra), event); err != nil {
+					onError(err)
+					continue
+				}
+				if event.Origin == h.instanceID {
+					continue
+				}
+				handler(event)
+			case <-ticker.C:
+				go func() {
+					if err := listener.Ping(); err != nil {
+						onError(err)
+					}
+				}()
+			}
+		}
+	}()
+
+	return listener, nil
+}
+
+// PurgeEpisodeCache delete all caches of episode
+func (h *DbHelper) PurgeEpisodeCache(episodeID int64) (int64, error) {
+	var total int64
+	aff, err := models.PlayURLCaches(models.PlayURLCachWhere.EpisodeID.EQ(episodeID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	aff, err = models.THEpisodeCaches(models.THEpisodeCachWhere.EpisodeID.EQ(episodeID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	aff, err = models.THSubtitleCaches(models.THSubtitleCachWhere.EpisodeID.EQ(episodeID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	aff, err = models.EpisodeAreaCaches(models.EpisodeAreaCachWhere.EpisodeID.EQ(episodeID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	return total, nil
+}
+
+// PurgeSeasonCache delete all caches of season
+func (h *DbHelper) PurgeSeasonCache(seasonID int64) (int64, error) {
+	var total int64
+	aff, err := models.THSeasonCaches(models.THSeasonCachWhere.SeasonID.EQ(seasonID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	aff, err = models.THSeason2Caches(models.THSeason2CachWhere.SeasonID.EQ(seasonID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	aff, err = models.THSeasonEpisodeCaches(models.THSeasonEpisodeCachWhere.SeasonID.EQ(seasonID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	aff, err = models.THSeason2EpisodeCaches(models.THSeason2EpisodeCachWhere.SeasonID.EQ(seasonID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	aff, err = models.SeasonAreaCaches(models.SeasonAreaCachWhere.SeasonID.EQ(seasonID)).DeleteAll(h.ctx, h.db)
+	if err != nil {
+		return total, err
+	}
+	total += aff
+	return total, nil
+}
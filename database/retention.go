@@ -0,0 +1,99 @@
+package database
+
+import (
+	"time"
+)
+
+const defaultRetentionBatchSize = 1000
+
+// execBatches run statement until fewer rows than batch size are affected,
+// every batch is a short transaction so tables are never locked for long
+func (h *DbHelper) execBatches(query string, batchSize int, args ...interface{}) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultRetentionBatchSize
+	}
+	args = append(args, batchSize)
+
+	var total int64
+	for {
+		res, err := h.db.ExecContext(h.ctx, query, args...)
+		if err != nil {
+			return total, err
+		}
+		aff, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += aff
+		if aff < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// PurgeInactiveAccessKeys delete access keys not used for duration
+func (h *DbHelper) PurgeInactiveAccessKeys(duration time.Duration, batchSize int) (int64, error) {
+	startTS := time.Now().Add(-duration).UTC()
+	return h.execBatches(`DELETE FROM access_keys WHERE key IN (
+		SELECT key FROM access_keys WHERE updated_at <= $1 LIMIT $2 FOR UPDATE SKIP LOCKED
+	)`, batchSize, startTS)
+}
+
+// PurgeInactiveCookieSessions delete SESSDATA cookies not used for duration
+func (h *DbHelper) PurgeInactiveCookieSessions(duration time.Duration, batchSize int) (int64, error) {
+	startTS := time.Now().Add(-duration).UTC()
+	return h.execBatches(`DELETE FROM cookie_sessions WHERE key IN (
+		SELECT key FROM cookie_sessions WHERE updated_at <= $1 LIMIT $2 FOR UPDATE SKIP LOCKED
+	)`, batchSize, startTS)
+}
+
+// PurgeInactiveUsers delete users not seen for duration and without access keys
+func (h *DbHelper) PurgeInactiveUsers(duration time.Duration, batchSize int) (int64, error) {
+	startTS := time.Now().Add(-duration).UTC()
+	return h.execBatches(`DELETE FROM users WHERE uid IN (
+		SELECT uid FROM users u WHERE updated_at <= $1
+		AND NOT EXISTS (SELECT 1 FROM access_keys a WHERE a.uid = u.uid)
+		AND NOT EXISTS (SELECT 1 FROM cookie_sessions c WHERE c.uid = u.uid)
+		LIMIT $2 FOR UPDATE SKIP LOCKED
+	)`, batchSize, startTS)
+}
+
+// AnonymizeInactiveUsers clear name of users not seen for duration and without access keys,
+// uid and vip due date are kept
+func (h *DbHelper) AnonymizeInactiveUsers(duration time.Duration, batchSize int) (int64, error) {
+	startTS := time.Now().Add(-duration).UTC()
+	return h.execBatches(`UPDATE users SET name = '' WHERE uid IN (
+		SELECT uid FROM users u WHERE updated_at <= $1 AND name <> ''
+		AND NOT EXISTS (SELECT 1 FROM access_keys a WHERE a.uid = u.uid)
+		AND NOT EXISTS (SELECT 1 FROM cookie_sessions c WHERE c.uid = u.uid)
+		LIMIT $2 FOR UPDATE SKIP LOCKED
+	)`, batchSize, startTS)
+}
+
+// ForgetUser delete all data of user, include shared auth cache
+func (h *DbHelper) ForgetUser(uid int64) (int64, error) {
+	tx, err := h.db.BeginTx(h.ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var total int64
+	for _, query := range []string{
+		`DELETE FROM auth_caches WHERE uid = $1`,
+		`DELETE FROM access_keys WHERE uid = $1`,
+		`DELETE FROM cookie_sessions WHERE uid = $1`,
+		`DELETE FROM users WHERE uid = $1`,
+	} {
+		res, err := tx.ExecContext(h.ctx, query, uid)
+		if err != nil {
+			return 0, err
+		}
+		aff, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += aff
+	}
+	return total, tx.Commit()
+}
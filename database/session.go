@@ -0,0 +1,51 @@
+package database
+
+import (
+	"time"
+)
+
+// RevokeSessions reject session tokens of user issued before timestamp
+func (h *DbHelper) RevokeSessions(uid int64, before time.Time) error {
+	_, err := h.db.ExecContext(h.ctx, `
+INSERT INTO session_revocations (uid, revoked_before) VALUES ($1, $2)
+ON CONFLICT (uid) DO UPDATE SET revoked_before = GREATEST(session_revocations.revoked_before, EXCLUDED.revoked_before)`,
+		uid, before.UTC())
+	return err
+}
+
+// GetSessionRevocation get revocation timestamp of user
+func (h *DbHelper) GetSessionRevocation(uid int64) (time.Time, error) {
+	var before time.Time
+	err := h.db.QueryRowContext(h.ctx, `SELECT revoked_before FROM session_revocations WHERE uid = $1`, uid).Scan(&before)
+	return before, err
+}
+
+// GetSessionRevocations get all revocation timestamps
+func (h *DbHelper) GetSessionRevocations() (map[int64]time.Time, error) {
+	rows, err := h.db.QueryContext(h.ctx, `SELECT uid, revoked_before FROM session_revocations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revocations := make(map[int64]time.Time)
+	for rows.Next() {
+		var uid int64
+		var before time.Time
+		if err := rows.Scan(&uid, &before); err != nil {
+			return nil, err
+		}
+		revocations[uid] = before
+	}
+	return revocations, rows.Err()
+}
+
+// CleanupSessionRevocations cleanup revocations older than token lifetime
+func (h *DbHelper) CleanupSessionRevocations(duration time.Duration) (int64, error) {
+	startTS := time.Now().Add(-duration).UTC()
+	result, err := h.db.ExecContext(h.ctx, `DELETE FROM session_revocations WHERE revoked_before <= $1`, startTS)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
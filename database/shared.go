@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// AuthCache shared auth cache data
+type AuthCache struct {
+	UID         int64
+	IsLogin     bool
+	IsVip       bool
+	IsBlacklist bool
+	IsWhitelist bool
+	BanUntil    time.Time
+	UpdatedAt   time.Time
+}
+
+// AllowRateLimit take one token from shared token bucket, return whether allowed and tokens left
+func (h *DbHelper) AllowRateLimit(key string, rate float64, burst int) (bool, float64, error) {
+	var tokens float64
+	err := h.db.QueryRowContext(h.ctx, `
+INSERT INTO rate_limits AS r (key, tokens, updated_at)
+VALUES ($1, $3::DOUBLE PRECISION - 1, NOW())
+ON CONFLICT (key) DO UPDATE SET
+    tokens = LEAST($3::DOUBLE PRECISION, r.tokens + EXTRACT(EPOCH FROM (NOW() - r.updated_at)) * $2) - 1,
+    updated_at = NOW()
+WHERE LEAST($3::DOUBLE PRECISION, r.tokens + EXTRACT(EPOCH FROM (NOW() - r.updated_at)) * $2) >= 1
+RETURNING tokens`, key, rate, burst).Scan(&tokens)
+	if err == nil {
+		return true, tokens, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, 0, err
+	}
+
+	// rejected, report current tokens
+	tokens, err = h.PeekRateLimit(key, rate, burst)
+	if err != nil {
+		return false, 0, err
+	}
+	return false, tokens, nil
+}
+
+// PeekRateLimit tokens left in shared token bucket without taking one
+func (h *DbHelper) PeekRateLimit(key string, rate float64, burst int) (float64, error) {
+	var tokens float64
+	err := h.db.QueryRowContext(h.ctx, `
+SELECT LEAST($3::DOUBLE PRECISION, tokens + EXTRACT(EPOCH FROM (NOW() - updated_at)) * $2)
+FROM rate_limits WHERE key = $1`, key, rate, burst).Scan(&tokens)
+	if errors.Is(err, sql.ErrNoRows) {
+		return float64(burst), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return tokens, nil
+}
+
+// CleanupRateLimits cleanup rate limits if exceeds duration
+func (h *DbHelper) CleanupRateLimits(duration time.Duration) (int64, error) {
+	result, err := h.db.ExecContext(h.ctx, `DELETE FROM rate_limits WHERE updated_at <= NOW() - $1 * INTERVAL '1 second'`, duration.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetAuthCache get shared auth cache
+func (h *DbHelper) GetAuthCache(key string) (*AuthCache, error) {
+	c := &AuthCache{}
+	err := h.db.QueryRowContext(h.ctx, `
+SELECT uid, is_login, is_vip, is_blacklist, is_whitelist, ban_until, updated_at
+FROM auth_caches WHERE key = $1`, h.hashKey(key)).Scan(&c.UID, &c.IsLogin, &c.IsVip, &c.IsBlacklist, &c.IsWhitelist, &c.BanUntil, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// InsertOrUpdateAuthCache insert or update shared auth cache
+func (h *DbHelper) InsertOrUpdateAuthCache(key string, c *AuthCache) error {
+	_, err := h.db.ExecContext(h.ctx, `
+INSERT INTO auth_caches (key, uid, is_login, is_vip, is_blacklist, is_whitelist, ban_until, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (key) DO UPDATE SET
+    uid = EXCLUDED.uid,
+    is_login = EXCLUDED.is_login,
+    is_vip = EXCLUDED.is_vip,
+    is_blacklist = EXCLUDED.is_blacklist,
+    is_whitelist = EXCLUDED.is_whitelist,
+    ban_until = EXCLUDED.ban_until,
+    updated_at = EXCLUDED.updated_at`,
+		h.hashKey(key), c.UID, c.IsLogin, c.IsVip, c.IsBlacklist, c.IsWhitelist, c.BanUntil.UTC(), c.UpdatedAt.UTC())
+	return err
+}
+
+// DeleteAuthCache delete shared auth cache
+func (h *DbHelper) DeleteAuthCache(key string) error {
+	_, err := h.db.ExecContext(h.ctx, `DELETE FROM auth_caches WHERE key = $1`, h.hashKey(key))
+	return err
+}
+
+// DeleteAuthCacheByUID delete shared auth caches of user
+func (h *DbHelper) DeleteAuthCacheByUID(uid int64) error {
+	_, err := h.db.ExecContext(h.ctx, `DELETE FROM auth_caches WHERE uid = $1`, uid)
+	return err
+}
+
+// CleanupAuthCaches cleanup shared auth caches if exceeds duration
+func (h *DbHelper) CleanupAuthCaches(duration time.Duration) (int64, error) {
+	startTS := time.Now().Add(-duration).UTC()
+	result, err := h.db.ExecContext(h.ctx, `DELETE FROM auth_caches WHERE updated_at <= $1`, startTS)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+type JobsResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    []JobStatus `json:"data"`
+}
+
+type JobStatus struct {
+	Name       string   `json:"name"`
+	Enabled    bool     `json:"enabled"`
+	LeaderOnly bool     `json:"leader_only"`
+	Interval   string   `json:"interval"`
+	Jitter     string   `json:"jitter"`
+	Running    bool     `json:"running"`
+	Runs       int64    `json:"runs"`
+	Failures   int64    `json:"failures"`
+	History    []JobRun `json:"history"`
+}
+
+type JobRun struct {
+	StartedAt    time.Time `json:"started_at"`
+	DurationMs   int64     `json:"duration_ms"`
+	RowsAffected int64     `json:"rows_affected"`
+	Error        string    `json:"error,omitempty"`
+	Skipped      bool      `json:"skipped,omitempty"`
+}
@@ -0,0 +1,15 @@
+package bstar
+
+type MyInfoResult struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Mid  int64  `json:"mid"`
+		Name string `json:"name"`
+		Vip  struct {
+			Type    int   `json:"type"`
+			Status  int   `json:"status"`
+			DueDate int64 `json:"due_date"`
+		} `json:"vip"`
+	} `json:"data"`
+}
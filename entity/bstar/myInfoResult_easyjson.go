@@ -0,0 +1,235 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package bstar
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson29555c4dDecodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(in *jlexer.Lexer, out *MyInfoResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "code":
+			out.Code = int(in.Int())
+		case "message":
+			out.Message = string(in.String())
+		case "data":
+			easyjson29555c4dDecode(in, &out.Data)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson29555c4dEncodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(out *jwriter.Writer, in MyInfoResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"code\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Code))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	{
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		easyjson29555c4dEncode(out, in.Data)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MyInfoResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson29555c4dEncodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MyInfoResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson29555c4dEncodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MyInfoResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson29555c4dDecodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MyInfoResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson29555c4dDecodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(l, v)
+}
+func easyjson29555c4dDecode(in *jlexer.Lexer, out *struct {
+	Mid  int64  `json:"mid"`
+	Name string `json:"name"`
+	Vip  struct {
+		Type    int   `json:"type"`
+		Status  int   `json:"status"`
+		DueDate int64 `json:"due_date"`
+	} `json:"vip"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "mid":
+			out.Mid = int64(in.Int64())
+		case "name":
+			out.Name = string(in.String())
+		case "vip":
+			easyjson29555c4dDecode1(in, &out.Vip)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson29555c4dEncode(out *jwriter.Writer, in struct {
+	Mid  int64  `json:"mid"`
+	Name string `json:"name"`
+	Vip  struct {
+		Type    int   `json:"type"`
+		Status  int   `json:"status"`
+		DueDate int64 `json:"due_date"`
+	} `json:"vip"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"mid\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Mid))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"vip\":"
+		out.RawString(prefix)
+		easyjson29555c4dEncode1(out, in.Vip)
+	}
+	out.RawByte('}')
+}
+func easyjson29555c4dDecode1(in *jlexer.Lexer, out *struct {
+	Type    int   `json:"type"`
+	Status  int   `json:"status"`
+	DueDate int64 `json:"due_date"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "type":
+			out.Type = int(in.Int())
+		case "status":
+			out.Status = int(in.Int())
+		case "due_date":
+			out.DueDate = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson29555c4dEncode1(out *jwriter.Writer, in struct {
+	Type    int   `json:"type"`
+	Status  int   `json:"status"`
+	DueDate int64 `json:"due_date"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Type))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.Int(int(in.Status))
+	}
+	{
+		const prefix string = ",\"due_date\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.DueDate))
+	}
+	out.RawByte('}')
+}
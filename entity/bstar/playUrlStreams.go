@@ -0,0 +1,19 @@
+package bstar
+
+// PlayUrlStreams stream vip info of playurl, for vip status detection
+type PlayUrlStreams struct {
+	Code int `json:"code"`
+	Data struct {
+		VideoInfo struct {
+			StreamList []struct {
+				StreamInfo struct {
+					Quality int  `json:"quality"`
+					NeedVip bool `json:"need_vip"`
+				} `json:"stream_info"`
+				DashVideo *struct {
+					BaseUrl string `json:"base_url"`
+				} `json:"dash_video"`
+			} `json:"stream_list"`
+		} `json:"video_info"`
+	} `json:"data"`
+}
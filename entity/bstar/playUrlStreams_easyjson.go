@@ -0,0 +1,456 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package bstar
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson7a0b50c8DecodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(in *jlexer.Lexer, out *PlayUrlStreams) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "code":
+			out.Code = int(in.Int())
+		case "data":
+			easyjson7a0b50c8Decode(in, &out.Data)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson7a0b50c8EncodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(out *jwriter.Writer, in PlayUrlStreams) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"code\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Code))
+	}
+	{
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		easyjson7a0b50c8Encode(out, in.Data)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PlayUrlStreams) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson7a0b50c8EncodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PlayUrlStreams) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson7a0b50c8EncodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PlayUrlStreams) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson7a0b50c8DecodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PlayUrlStreams) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson7a0b50c8DecodeGithubComJasonKhew96BiliroamingGoServerEntityBstar(l, v)
+}
+func easyjson7a0b50c8Decode(in *jlexer.Lexer, out *struct {
+	VideoInfo struct {
+		StreamList []struct {
+			StreamInfo struct {
+				Quality int  `json:"quality"`
+				NeedVip bool `json:"need_vip"`
+			} `json:"stream_info"`
+			DashVideo *struct {
+				BaseUrl string `json:"base_url"`
+			} `json:"dash_video"`
+		} `json:"stream_list"`
+	} `json:"video_info"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "video_info":
+			easyjson7a0b50c8Decode1(in, &out.VideoInfo)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson7a0b50c8Encode(out *jwriter.Writer, in struct {
+	VideoInfo struct {
+		StreamList []struct {
+			StreamInfo struct {
+				Quality int  `json:"quality"`
+				NeedVip bool `json:"need_vip"`
+			} `json:"stream_info"`
+			DashVideo *struct {
+				BaseUrl string `json:"base_url"`
+			} `json:"dash_video"`
+		} `json:"stream_list"`
+	} `json:"video_info"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"video_info\":"
+		out.RawString(prefix[1:])
+		easyjson7a0b50c8Encode1(out, in.VideoInfo)
+	}
+	out.RawByte('}')
+}
+func easyjson7a0b50c8Decode1(in *jlexer.Lexer, out *struct {
+	StreamList []struct {
+		StreamInfo struct {
+			Quality int  `json:"quality"`
+			NeedVip bool `json:"need_vip"`
+		} `json:"stream_info"`
+		DashVideo *struct {
+			BaseUrl string `json:"base_url"`
+		} `json:"dash_video"`
+	} `json:"stream_list"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "stream_list":
+			if in.IsNull() {
+				in.Skip()
+				out.StreamList = nil
+			} else {
+				in.Delim('[')
+				if out.StreamList == nil {
+					if !in.IsDelim(']') {
+						out.StreamList = make([]struct {
+							StreamInfo struct {
+								Quality int  `json:"quality"`
+								NeedVip bool `json:"need_vip"`
+							} `json:"stream_info"`
+							DashVideo *struct {
+								BaseUrl string `json:"base_url"`
+							} `json:"dash_video"`
+						}, 0, 2)
+					} else {
+						out.StreamList = []struct {
+							StreamInfo struct {
+								Quality int  `json:"quality"`
+								NeedVip bool `json:"need_vip"`
+							} `json:"stream_info"`
+							DashVideo *struct {
+								BaseUrl string `json:"base_url"`
+							} `json:"dash_video"`
+						}{}
+					}
+				} else {
+					out.StreamList = (out.StreamList)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 struct {
+						StreamInfo struct {
+							Quality int  `json:"quality"`
+							NeedVip bool `json:"need_vip"`
+						} `json:"stream_info"`
+						DashVideo *struct {
+							BaseUrl string `json:"base_url"`
+						} `json:"dash_video"`
+					}
+					easyjson7a0b50c8Decode2(in, &v1)
+					out.StreamList = append(out.StreamList, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson7a0b50c8Encode1(out *jwriter.Writer, in struct {
+	StreamList []struct {
+		StreamInfo struct {
+			Quality int  `json:"quality"`
+			NeedVip bool `json:"need_vip"`
+		} `json:"stream_info"`
+		DashVideo *struct {
+			BaseUrl string `json:"base_url"`
+		} `json:"dash_video"`
+	} `json:"stream_list"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"stream_list\":"
+		out.RawString(prefix[1:])
+		if in.StreamList == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.StreamList {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				easyjson7a0b50c8Encode2(out, v3)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+func easyjson7a0b50c8Decode2(in *jlexer.Lexer, out *struct {
+	StreamInfo struct {
+		Quality int  `json:"quality"`
+		NeedVip bool `json:"need_vip"`
+	} `json:"stream_info"`
+	DashVideo *struct {
+		BaseUrl string `json:"base_url"`
+	} `json:"dash_video"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "stream_info":
+			easyjson7a0b50c8Decode3(in, &out.StreamInfo)
+		case "dash_video":
+			if in.IsNull() {
+				in.Skip()
+				out.DashVideo = nil
+			} else {
+				if out.DashVideo == nil {
+					out.DashVideo = new(struct {
+						BaseUrl string `json:"base_url"`
+					})
+				}
+				easyjson7a0b50c8Decode4(in, out.DashVideo)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson7a0b50c8Encode2(out *jwriter.Writer, in struct {
+	StreamInfo struct {
+		Quality int  `json:"quality"`
+		NeedVip bool `json:"need_vip"`
+	} `json:"stream_info"`
+	DashVideo *struct {
+		BaseUrl string `json:"base_url"`
+	} `json:"dash_video"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"stream_info\":"
+		out.RawString(prefix[1:])
+		easyjson7a0b50c8Encode3(out, in.StreamInfo)
+	}
+	{
+		const prefix string = ",\"dash_video\":"
+		out.RawString(prefix)
+		if in.DashVideo == nil {
+			out.RawString("null")
+		} else {
+			easyjson7a0b50c8Encode4(out, *in.DashVideo)
+		}
+	}
+	out.RawByte('}')
+}
+func easyjson7a0b50c8Decode4(in *jlexer.Lexer, out *struct {
+	BaseUrl string `json:"base_url"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "base_url":
+			out.BaseUrl = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson7a0b50c8Encode4(out *jwriter.Writer, in struct {
+	BaseUrl string `json:"base_url"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"base_url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.BaseUrl))
+	}
+	out.RawByte('}')
+}
+func easyjson7a0b50c8Decode3(in *jlexer.Lexer, out *struct {
+	Quality int  `json:"quality"`
+	NeedVip bool `json:"need_vip"`
+}) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "quality":
+			out.Quality = int(in.Int())
+		case "need_vip":
+			out.NeedVip = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson7a0b50c8Encode3(out *jwriter.Writer, in struct {
+	Quality int  `json:"quality"`
+	NeedVip bool `json:"need_vip"`
+}) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"quality\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Quality))
+	}
+	{
+		const prefix string = ",\"need_vip\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.NeedVip))
+	}
+	out.RawByte('}')
+}
@@ -0,0 +1,25 @@
+package entity
+
+type MeResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    MeData `json:"data"`
+}
+
+type MeData struct {
+	UID         int64       `json:"uid"`
+	AccountType string      `json:"account_type"`
+	IsVip       bool        `json:"is_vip"`
+	IsBlacklist bool        `json:"is_blacklist"`
+	IsWhitelist bool        `json:"is_whitelist"`
+	BanUntil    int64       `json:"ban_until"`
+	Group       string      `json:"group"`
+	RateLimit   MeRateLimit `json:"rate_limit"`
+	Areas       []string    `json:"areas"`
+}
+
+type MeRateLimit struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+// SessionClaims payload of server issued session token
+type SessionClaims struct {
+	UID       int64  `json:"uid"`
+	IsVip     bool   `json:"vip"`
+	Group     string `json:"group"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	// IssuedAtNano compared with revocation, iat has second precision only
+	IssuedAtNano int64 `json:"iat_ns,omitempty"`
+}
+
+type SessionResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    SessionData `json:"data"`
+}
+
+type SessionData struct {
+	Token     string `json:"token"`
+	UID       int64  `json:"uid"`
+	IsVip     bool   `json:"is_vip"`
+	ExpiresAt int64  `json:"expires_at"`
+}
@@ -0,0 +1,14 @@
+package web
+
+// NavResult web account info from cookie
+type NavResult struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		IsLogin    bool   `json:"isLogin"`
+		Mid        int64  `json:"mid"`
+		Uname      string `json:"uname"`
+		VipDueDate int64  `json:"vipDueDate"`
+		VipStatus  int    `json:"vipStatus"`
+	} `json:"data"`
+}
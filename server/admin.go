@@ -0,0 +1,159 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/valyala/fasthttp"
+)
+
+func getAdminToken(c *Config) (string, error) {
+	token := c.Admin.Token
+	if c.Admin.TokenFile != "" {
+		data, err := os.ReadFile(c.Admin.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		if len(data) > 0 {
+			token = strings.TrimSpace(string(data))
+		}
+	}
+	return token, nil
+}
+
+// checkAdmin admin api disabled without token
+func (b *BiliroamingGo) checkAdmin(ctx *fasthttp.RequestCtx) bool {
+	if b.adminToken == "" {
+		processNotFound(ctx)
+		return false
+	}
+	auth := ctx.Request.Header.Peek(fasthttp.HeaderAuthorization)
+	if !bytes.HasPrefix(auth, []byte("Bearer ")) || subtle.ConstantTimeCompare(auth[len("Bearer "):], []byte(b.adminToken)) != 1 {
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_ADMIN, MSG_ERROR_AUTH_ADMIN)
+		return false
+	}
+	return true
+}
+
+func (b *BiliroamingGo) handleAdminJobs(ctx *fasthttp.RequestCtx) {
+	if !b.checkAdmin(ctx) {
+		return
+	}
+	writeJSON(ctx, &entity.JobsResponse{
+		Code:    0,
+		Message: "0",
+		Data:    b.scheduler.status(),
+	})
+}
+
+func (b *BiliroamingGo) handleAdminRunJob(ctx *fasthttp.RequestCtx) {
+	if !b.checkAdmin(ctx) {
+		return
+	}
+	if !ctx.IsPost() {
+		writeErrorJSON(ctx, ERROR_CODE_METHOD_NOT_ALLOWED, MSG_ERROR_METHOD_NOT_ALLOWED)
+		return
+	}
+	name := string(ctx.QueryArgs().Peek("name"))
+	if err := b.scheduler.trigger(name); err != nil {
+		if errors.Is(err, ErrNotLeader) {
+			writeErrorJSON(ctx, ERROR_CODE_JOB_NOT_LEADER, MSG_ERROR_JOB_NOT_LEADER)
+		} else {
+			writeErrorJSON(ctx, ERROR_CODE_JOB_NOT_FOUND, MSG_ERROR_JOB_NOT_FOUND)
+		}
+		return
+	}
+	writeJSON(ctx, &entity.SimpleResponse{
+		Code:    0,
+		Message: "0",
+	})
+}
+
+func (b *BiliroamingGo) handleAdminForgetUser(ctx *fasthttp.RequestCtx) {
+	if !b.checkAdmin(ctx) {
+		return
+	}
+	if !ctx.IsPost() {
+		writeErrorJSON(ctx, ERROR_CODE_METHOD_NOT_ALLOWED, MSG_ERROR_METHOD_NOT_ALLOWED)
+		return
+	}
+	uid, err := ctx.QueryArgs().GetUint("uid")
+	if err != nil || uid <= 0 {
+		writeErrorJSON(ctx, ERROR_CODE_PARAMETERS, MSG_ERROR_PARAMETERS)
+		return
+	}
+	if _, err := b.forgetUser(int64(uid)); err != nil {
+		b.sugar.Error(err)
+		writeErrorJSON(ctx, ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+		return
+	}
+	writeJSON(ctx, &entity.SimpleResponse{
+		Code:    0,
+		Message: "0",
+	})
+}
+
+func (b *BiliroamingGo) handleAdminRevokeSessions(ctx *fasthttp.RequestCtx) {
+	if !b.checkAdmin(ctx) {
+		return
+	}
+	if !ctx.IsPost() {
+		writeErrorJSON(ctx, ERROR_CODE_METHOD_NOT_ALLOWED, MSG_ERROR_METHOD_NOT_ALLOWED)
+		return
+	}
+	uid, err := ctx.QueryArgs().GetUint("uid")
+	if err != nil || uid <= 0 {
+		writeErrorJSON(ctx, ERROR_CODE_PARAMETERS, MSG_ERROR_PARAMETERS)
+		return
+	}
+	if err := b.revokeSessions(int64(uid)); err != nil {
+		b.sugar.Error(err)
+		writeErrorJSON(ctx, ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+		return
+	}
+	writeJSON(ctx, &entity.SimpleResponse{
+		Code:    0,
+		Message: "0",
+	})
+}
+
+// handleAdminPurgeCache purge caches of episode_id or season_id on all instances
+func (b *BiliroamingGo) handleAdminPurgeCache(ctx *fasthttp.RequestCtx) {
+	if !b.checkAdmin(ctx) {
+		return
+	}
+	if !ctx.IsPost() {
+		writeErrorJSON(ctx, ERROR_CODE_METHOD_NOT_ALLOWED, MSG_ERROR_METHOD_NOT_ALLOWED)
+		return
+	}
+	var err error
+	if episodeId, e := ctx.QueryArgs().GetUint("episode_id"); e == nil && episodeId > 0 {
+		_, err = b.purgeEpisodeCache(int64(episodeId))
+	} else if seasonId, e := ctx.QueryArgs().GetUint("season_id"); e == nil && seasonId > 0 {
+		_, err = b.purgeSeasonCache(int64(seasonId))
+	} else {
+		writeErrorJSON(ctx, ERROR_CODE_PARAMETERS, MSG_ERROR_PARAMETERS)
+		return
+	}
+	if err != nil {
+		b.sugar.Error(err)
+		writeErrorJSON(ctx, ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+		return
+	}
+	writeJSON(ctx, &entity.SimpleResponse{
+		Code:    0,
+		Message: "0",
+	})
+}
+
+func (b *BiliroamingGo) handleAdminMetrics(ctx *fasthttp.RequestCtx) {
+	if !b.checkAdmin(ctx) {
+		return
+	}
+	ctx.SetContentType("text/plain; version=0.0.4")
+	b.metrics.writeTo(ctx)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AppKey appkey 注册表项
+type AppKey struct {
+	ClientType ClientType `yaml:"clientType"`
+	Appkey     string     `yaml:"appkey"`
+	Appsec     string     `yaml:"appsec"`
+	MobiApp    string     `yaml:"mobiApp"`
+	Platform   string     `yaml:"platform"`
+	Build      int        `yaml:"build"`
+}
+
+// defaultAppKeys built-in appkey and appsec
+// taken from https://github.com/yujincheng08/BiliRoaming/wiki/%E8%87%AA%E5%BB%BA%E8%A7%A3%E6%9E%90%E6%9C%8D%E5%8A%A1%E5%99%A8#api-%E8%AF%B7%E6%B1%82%E7%AD%BE%E5%90%8D
+var defaultAppKeys = []AppKey{
+	{ClientType: ClientTypeAi4cCreatorAndroid, Appkey: "9d5889cf67e615cd", Appsec: "8fd9bb32efea8cef801fd895bef2713d"},
+	{ClientType: ClientTypeAndroid, Appkey: "1d8b6e7d45233436", Appsec: "560c52ccd288fed045859ed18bffd973", Build: 6400000},
+	{ClientType: ClientTypeAndroidB, Appkey: "07da50c9a0bf829f", Appsec: "25bdede4e1581c836cab73a48790ca6e"},
+	{ClientType: ClientTypeAndroidBiliThings, Appkey: "8d23902c1688a798", Appsec: "710f0212e62bd499b8d3ac6e1db9302a"},
+	{ClientType: ClientTypeAndroidHD, Appkey: "dfca71928277209b", Appsec: "b5475a8825547a4fc26c7d518eaaa02e"},
+	{ClientType: ClientTypeAndroidI, Appkey: "bb3101000e232e27", Appsec: "36efcfed79309338ced0380abd824ac1"},
+	{ClientType: ClientTypeAndroidMallTicket, Appkey: "4c6e1021617d40d9", Appsec: "e559a59044eb2701b7a8628c86aa12ae"},
+	{ClientType: ClientTypeAndroidOttSdk, Appkey: "c034e8b74130a886", Appsec: "e4e8966b1e71847dc4a3830f2d078523"},
+	{ClientType: ClientTypeAndroidTV, Appkey: "4409e2ce8ffd12b8", Appsec: "59b43e04ad6965f34319062b478f83dd"},
+	{ClientType: ClientTypeAnguAndroid, Appkey: "50e1328c6a1075a1", Appsec: "4d35e3dea073433cd24dd14b503d242e"},
+	{ClientType: ClientTypeBiliLink, Appkey: "37207f2beaebf8d7", Appsec: "e988e794d4d4b6dd43bc0e89d6e90c43"},
+	{ClientType: ClientTypeBiliScan, Appkey: "9a75abf7de2d8947", Appsec: "35ca1c82be6c2c242ecc04d88c735f31"},
+	{ClientType: ClientTypeBstarA, Appkey: "7d089525d3611b1c", Appsec: "acd495b248ec528c2eed1e862d393126", Build: 1080003},
+	{ClientType: ClientTypeIphone, Appkey: "27eb53fc9058f8c3", Appsec: "c2ed53a74eeefe3cf99fbd01d8c9c375", Platform: "ios"},
+}
+
+// appKeyRegistry first entry of client type is used for outbound signing,
+// every entry is accepted for sign verification
+type appKeyRegistry struct {
+	byAppkey     map[string]*AppKey
+	byClientType map[ClientType]*AppKey
+}
+
+// newAppKeyRegistry config entries take precedence over built-in defaults
+func newAppKeyRegistry(entries []AppKey) (*appKeyRegistry, error) {
+	r := &appKeyRegistry{
+		byAppkey:     make(map[string]*AppKey),
+		byClientType: make(map[ClientType]*AppKey),
+	}
+	for i := range entries {
+		if entries[i].ClientType == "" || entries[i].Appkey == "" || entries[i].Appsec == "" {
+			return nil, fmt.Errorf("appKeys[%d]: clientType, appkey and appsec are required", i)
+		}
+		r.add(entries[i])
+	}
+	for _, entry := range defaultAppKeys {
+		r.add(entry)
+	}
+	return r, nil
+}
+
+func (r *appKeyRegistry) add(entry AppKey) {
+	if entry.MobiApp == "" {
+		entry.MobiApp = entry.ClientType.String()
+	}
+	if entry.Platform == "" {
+		entry.Platform = "android"
+	}
+	if _, ok := r.byAppkey[entry.Appkey]; !ok {
+		r.byAppkey[entry.Appkey] = &entry
+	}
+	if _, ok := r.byClientType[entry.ClientType]; !ok {
+		r.byClientType[entry.ClientType] = &entry
+	}
+}
+
+func (r *appKeyRegistry) get(clientType ClientType) (*AppKey, bool) {
+	entry, ok := r.byClientType[clientType]
+	return entry, ok
+}
+
+func (r *appKeyRegistry) lookup(appkey string) (*AppKey, bool) {
+	entry, ok := r.byAppkey[appkey]
+	return entry, ok
+}
+
+func (r *appKeyRegistry) isValid(clientType ClientType) bool {
+	if clientType == ClientTypeWeb {
+		return true
+	}
+	_, ok := r.byClientType[clientType]
+	return ok
+}
+
+// clientType unknown appkey fallback to iphone
+func (r *appKeyRegistry) clientType(appkey string) ClientType {
+	if entry, ok := r.lookup(appkey); ok {
+		return entry.ClientType
+	}
+	return ClientTypeIphone
+}
+
+// signWith nil entry sign with empty appkey and appsec
+func signWith(values url.Values, entry *AppKey, timestamp int64) string {
+	var appkey, appsec string
+	if entry != nil {
+		appkey, appsec = entry.Appkey, entry.Appsec
+	}
+
+	values.Set("ts", strconv.FormatInt(timestamp, 10))
+	values.Set("appkey", appkey)
+
+	encoded := values.Encode() + appsec
+	return fmt.Sprintf("%x", md5.Sum([]byte(encoded)))
+}
+
+// setMobiApp mobi_app of client type, for upstream APIs that require it
+func (r *appKeyRegistry) setMobiApp(values url.Values, clientType ClientType) {
+	if entry, ok := r.get(clientType); ok {
+		values.Set("mobi_app", entry.MobiApp)
+	}
+}
+
+// setPlatform platform of client type, for upstream APIs that require it
+func (r *appKeyRegistry) setPlatform(values url.Values, clientType ClientType) {
+	if entry, ok := r.get(clientType); ok {
+		values.Set("platform", entry.Platform)
+	}
+}
+
+// setBuild default build of client type, for upstream APIs that require it
+func (r *appKeyRegistry) setBuild(values url.Values, clientType ClientType) {
+	if entry, ok := r.get(clientType); ok && entry.Build > 0 {
+		values.Set("build", strconv.Itoa(entry.Build))
+	}
+}
+
+// signParams sign params according to client type
+func (r *appKeyRegistry) signParams(values url.Values, clientType ClientType, timestamp int64) string {
+	entry, _ := r.get(clientType)
+	values.Set("sign", signWith(values, entry, timestamp))
+	return values.Encode()
+}
+
+// SignParams sign params according to client type
+func (b *BiliroamingGo) SignParams(values url.Values, clientType ClientType) (string, error) {
+	return b.appKeys.signParams(values, clientType, time.Now().Unix()), nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSignParamsKeepsParams(t *testing.T) {
+	r, err := newAppKeyRegistry(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, clientType := range []ClientType{ClientTypeAndroid, ClientTypeIphone, ClientTypeBstarA} {
+		v := url.Values{}
+		v.Set("keyword", "test")
+		params, err := url.ParseQuery(r.signParams(v, clientType, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"mobi_app", "platform", "build"} {
+			if params.Has(name) {
+				t.Errorf("%s: unexpected %s=%s", clientType, name, params.Get(name))
+			}
+		}
+	}
+}
+
+func TestBstarClientParams(t *testing.T) {
+	r, err := newAppKeyRegistry(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := url.Values{}
+	r.setMobiApp(v, ClientTypeBstarA)
+	r.setPlatform(v, ClientTypeBstarA)
+	r.setBuild(v, ClientTypeBstarA)
+	want := url.Values{"mobi_app": {"bstar_a"}, "platform": {"android"}, "build": {"1080003"}}
+	if v.Encode() != want.Encode() {
+		t.Errorf("params = %s, want %s", v.Encode(), want.Encode())
+	}
+}
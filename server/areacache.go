@@ -0,0 +1,115 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/models"
+)
+
+const (
+	areaCacheDuration   = time.Minute
+	areaCacheMaxEntries = 100000
+)
+
+// areaCaches local copy of episode and season area availability in front of database,
+// evicted on local update and by update or purge events from other instances
+type areaCaches struct {
+	episodes *shardedCache[*models.EpisodeAreaCach]
+	seasons  *shardedCache[*models.SeasonAreaCach]
+}
+
+func newAreaCaches(m *metrics) *areaCaches {
+	return &areaCaches{
+		episodes: newShardedCache[*models.EpisodeAreaCach]("episode_area", areaCacheMaxEntries, areaCacheDuration, false, m),
+		seasons:  newShardedCache[*models.SeasonAreaCach]("season_area", areaCacheMaxEntries, areaCacheDuration, false, m),
+	}
+}
+
+func (c *areaCaches) cleanup() int64 {
+	return c.episodes.cleanup() + c.seasons.cleanup()
+}
+
+func (c *areaCaches) flush() {
+	c.episodes.flush()
+	c.seasons.flush()
+}
+
+func (b *BiliroamingGo) getEpisodeAreaCache(episodeId int64) (*models.EpisodeAreaCach, error) {
+	key := strconv.FormatInt(episodeId, 10)
+	if cache, ok := b.areas.episodes.get(key); ok {
+		return cache, nil
+	}
+	cache, err := b.db.GetEpisodeAreaCache(episodeId)
+	if err != nil {
+		return nil, err
+	}
+	b.areas.episodes.set(key, cache)
+	return cache, nil
+}
+
+func (b *BiliroamingGo) setEpisodeAreaCache(episodeId int64, area database.Area, isAvailable bool) error {
+	defer b.areas.episodes.delete(strconv.FormatInt(episodeId, 10))
+	if err := b.db.InsertOrUpdateEpisodeAreaCache(episodeId, area, isAvailable); err != nil {
+		return err
+	}
+	b.publishInvalidation(&database.InvalidationEvent{
+		Type:      database.InvalidationEpisodeCache,
+		EpisodeID: episodeId,
+	})
+	return nil
+}
+
+func (b *BiliroamingGo) getSeasonAreaCache(seasonId int64) (*models.SeasonAreaCach, error) {
+	key := strconv.FormatInt(seasonId, 10)
+	if cache, ok := b.areas.seasons.get(key); ok {
+		return cache, nil
+	}
+	cache, err := b.db.GetSeasonAreaCache(seasonId)
+	if err != nil {
+		return nil, err
+	}
+	b.areas.seasons.set(key, cache)
+	return cache, nil
+}
+
+func (b *BiliroamingGo) setSeasonAreaCache(seasonId int64, area database.Area, isAvailable bool) error {
+	defer b.areas.seasons.delete(strconv.FormatInt(seasonId, 10))
+	if err := b.db.InsertOrUpdateSeasonAreaCache(seasonId, area, isAvailable); err != nil {
+		return err
+	}
+	b.publishInvalidation(&database.InvalidationEvent{
+		Type:     database.InvalidationSeasonCache,
+		SeasonID: seasonId,
+	})
+	return nil
+}
+
+// purgeEpisodeCache delete all caches of episode on all instances
+func (b *BiliroamingGo) purgeEpisodeCache(episodeId int64) (int64, error) {
+	aff, err := b.db.PurgeEpisodeCache(episodeId)
+	b.areas.episodes.delete(strconv.FormatInt(episodeId, 10))
+	if err != nil {
+		return aff, err
+	}
+	b.publishInvalidation(&database.InvalidationEvent{
+		Type:      database.InvalidationEpisodeCache,
+		EpisodeID: episodeId,
+	})
+	return aff, nil
+}
+
+// purgeSeasonCache delete all caches of season on all instances
+func (b *BiliroamingGo) purgeSeasonCache(seasonId int64) (int64, error) {
+	aff, err := b.db.PurgeSeasonCache(seasonId)
+	b.areas.seasons.delete(strconv.FormatInt(seasonId, 10))
+	if err != nil {
+		return aff, err
+	}
+	b.publishInvalidation(&database.InvalidationEvent{
+		Type:     database.InvalidationSeasonCache,
+		SeasonID: seasonId,
+	})
+	return aff, nil
+}
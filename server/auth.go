@@ -0,0 +1,421 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/JasonKhew96/biliroaming-go-server/entity/bstar"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/idna"
+)
+
+// BlockTypeEnum block type
+type BlockTypeEnum int
+
+// BlockType
+const (
+	BlockTypeDisabled BlockTypeEnum = iota
+	BlockTypeEnabled
+	BlockTypeWhitelist
+)
+
+type userStatus struct {
+	isLogin     bool
+	isVip       bool
+	isBlacklist bool
+	isWhitelist bool
+	uid         int64
+	banUntil    time.Time
+	// keyless authenticated by session token or cookie, app api is requested as guest
+	keyless bool
+	// unauthenticated area does not require auth, user checks and caches are skipped
+	unauthenticated bool
+}
+
+// unauthenticatedStatus status of request to area without auth
+func unauthenticatedStatus() *userStatus {
+	return &userStatus{
+		uid:             -1,
+		unauthenticated: true,
+	}
+}
+
+// upstreamVip vip status of upstream response, guest unless credential is forwarded
+func (s *userStatus) upstreamVip(forwarded bool) bool {
+	return s.isVip && (!s.keyless || forwarded)
+}
+
+func (b *BiliroamingGo) getAuthByArea(area string) bool {
+	switch strings.ToLower(area) {
+	case "cn":
+		return b.config.Auth.CN
+	case "hk":
+		return b.config.Auth.HK
+	case "tw":
+		return b.config.Auth.TW
+	case "th":
+		return b.config.Auth.TH
+	default:
+		return true
+	}
+}
+
+func (b *BiliroamingGo) checkBWlist(ctx *fasthttp.RequestCtx, uid int64) (*entity.BlackWhitelist, error) {
+	mid, _ := splitUID(uid)
+	apiUrl := fmt.Sprintf(b.config.BlacklistApiUrl, mid)
+	reqParams := &HttpRequestParams{
+		Method:    []byte(fasthttp.MethodGet),
+		Url:       []byte(apiUrl),
+		UserAgent: []byte(DEFAULT_NAME),
+	}
+	data, err := b.doRequestJson(b.defaultClient, reqParams)
+	if err != nil {
+		return nil, err
+	}
+	blackwhitelist := &entity.BlackWhitelist{}
+	if err := easyjson.Unmarshal(data, blackwhitelist); err != nil {
+		return nil, err
+	}
+
+	return blackwhitelist, nil
+}
+
+// fillBWlist set blacklist and whitelist status of user
+func (b *BiliroamingGo) fillBWlist(ctx *fasthttp.RequestCtx, status *userStatus) error {
+	if b.config.BlockType == BlockTypeDisabled {
+		return nil
+	}
+	bwlist, err := b.checkBWlist(ctx, status.uid)
+	if err != nil {
+		return err
+	}
+	if bwlist.Code == 0 {
+		status.isBlacklist = bwlist.Data.Status == 1
+		status.isWhitelist = bwlist.Data.Status == 2
+
+		status.banUntil = time.Unix(bwlist.Data.BanUntil, 0)
+	}
+	return nil
+}
+
+func (b *BiliroamingGo) isAuth(ctx *fasthttp.RequestCtx, accessKey string, clientType ClientType, isForced bool) (*userStatus, error) {
+	userStatus := &userStatus{
+		uid: -1,
+	}
+
+	keyData, err := b.db.GetUserFromKey(accessKey)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		// unknown error
+		b.sugar.Error("GetUserFromKey error ", err)
+		return userStatus, err
+	} else if err == nil && !isForced && keyData.UpdatedAt.After(time.Now().Add(-b.config.Cache.User)) {
+		// cached
+		b.sugar.Debugf("Get vip status from cache: %d %s", keyData.UID, keyData.VipDueDate)
+
+		userStatus.uid = keyData.UID
+		userStatus.isLogin = true
+
+		b.sugar.Debugf("isAuth %d %s", keyData.UID, accessKey)
+		if err := b.fillBWlist(ctx, userStatus); err != nil {
+			return userStatus, err
+		}
+
+		if keyData.VipDueDate.After(time.Now()) {
+			userStatus.isVip = true
+		}
+
+		return userStatus, nil
+	}
+
+	account, err := b.getAccountInfo(ctx, accessKey, clientType)
+	if err != nil {
+		return userStatus, err
+	}
+	b.sugar.Debugf("mid: %d, name: %s, due_date: %s", account.uid, account.name, account.vipDue.String())
+
+	userStatus.uid = account.uid
+	userStatus.isLogin = true
+
+	vipDue := account.vipDue
+	err = b.db.InsertOrUpdateUser(account.uid, account.name, vipDue)
+	if err != nil {
+		return userStatus, err
+	}
+
+	if isForced || (keyData != nil && !keyData.VipDueDate.Equal(vipDue)) {
+		b.evictUser(account.uid, database.InvalidationUserReauth)
+	}
+
+	err = b.db.InsertOrUpdateKey(accessKey, account.uid, clientType.String())
+	if err != nil {
+		return userStatus, err
+	}
+
+	if vipDue.After(time.Now()) {
+		userStatus.isVip = true
+	}
+
+	if err := b.fillBWlist(ctx, userStatus); err != nil {
+		return userStatus, err
+	}
+
+	return userStatus, nil
+}
+
+// bstarUIDOffset bstar 与 bilibili 的 uid 互相独立，bstar 账号的 uid 加上偏移量后保存，
+// 限流、forget 等都使用偏移后的 uid，黑名单接口与 /api/me 使用原始 mid
+const bstarUIDOffset int64 = 1 << 62
+
+// account type of uid
+const (
+	accountTypeBilibili = "bilibili"
+	accountTypeBstar    = "bstar"
+)
+
+func bstarUID(mid int64) int64 {
+	return bstarUIDOffset + mid
+}
+
+// splitUID raw mid and account type of stored uid
+func splitUID(uid int64) (int64, string) {
+	if uid >= bstarUIDOffset {
+		return uid - bstarUIDOffset, accountTypeBstar
+	}
+	return uid, accountTypeBilibili
+}
+
+// accountInfo account of access key from bilibili or bstar
+type accountInfo struct {
+	uid    int64
+	name   string
+	vipDue time.Time
+}
+
+func (b *BiliroamingGo) getAccountInfo(ctx *fasthttp.RequestCtx, accessKey string, clientType ClientType) (*accountInfo, error) {
+	if clientType == ClientTypeBstarA {
+		body, err := b.getBstarMyInfo(ctx, accessKey)
+		if err != nil {
+			return nil, err
+		}
+		data := &bstar.MyInfoResult{}
+		if err := easyjson.Unmarshal(body, data); err != nil {
+			return nil, err
+		}
+		if data.Code != 0 {
+			return nil, errors.New(data.Message)
+		}
+		account := &accountInfo{
+			uid:    bstarUID(data.Data.Mid),
+			name:   data.Data.Name,
+			vipDue: time.Unix(0, 0),
+		}
+		if data.Data.Vip.Status == 1 {
+			account.vipDue = time.UnixMilli(data.Data.Vip.DueDate)
+		}
+		return account, nil
+	}
+
+	body, err := b.getMyInfo(ctx, accessKey, clientType)
+	if err != nil {
+		return nil, err
+	}
+	data := &entity.AccInfo{}
+	if err := easyjson.Unmarshal(body, data); err != nil {
+		return nil, err
+	}
+	if data.Code != 0 {
+		return nil, errors.New(data.Message)
+	}
+	return &accountInfo{
+		uid:    data.Data.Mid,
+		name:   data.Data.Name,
+		vipDue: time.Unix(data.Data.VIP.DueDate/1000, 0),
+	}, nil
+}
+
+func (b *BiliroamingGo) getBstarMyInfo(ctx *fasthttp.RequestCtx, accessKey string) ([]byte, error) {
+	v := url.Values{}
+	v.Set("access_key", accessKey)
+	b.appKeys.setMobiApp(v, ClientTypeBstarA)
+	v.Set("s_locale", "zh_SG")
+
+	params, err := b.SignParams(v, ClientTypeBstarA)
+	if err != nil {
+		return nil, err
+	}
+
+	reverseProxy := b.getReverseProxyByArea("th")
+	if reverseProxy == "" {
+		reverseProxy = "api.biliintl.com"
+	}
+	domain, err := idna.New().ToASCII(reverseProxy)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := fmt.Sprintf("https://%s/intl/gateway/v2/app/account/myinfo?%s", domain, params)
+
+	b.sugar.Debug(apiURL)
+
+	reqParams := &HttpRequestParams{
+		Method:    []byte(fasthttp.MethodGet),
+		Url:       []byte(apiURL),
+		UserAgent: ctx.UserAgent(),
+	}
+	body, err := b.doRequestJson(b.getClientByArea("th"), reqParams)
+	if err != nil {
+		return nil, err
+	}
+
+	b.sugar.Debug("Content: ", string(body))
+
+	return body, nil
+}
+
+func (b *BiliroamingGo) getMyInfo(ctx *fasthttp.RequestCtx, accessKey string, clientType ClientType) ([]byte, error) {
+	apiURL := "https://app.bilibili.com/x/v2/account/myinfo"
+
+	v := url.Values{}
+
+	v.Set("access_key", accessKey)
+
+	if clientType == ClientTypeUnknown {
+		clientType = ClientTypeIphone
+	}
+
+	params, err := b.SignParams(v, clientType)
+	if err != nil {
+		return nil, err
+	}
+	apiURL += "?" + params
+
+	b.sugar.Debug(apiURL)
+
+	reqParams := &HttpRequestParams{
+		Method:    []byte(fasthttp.MethodGet),
+		Url:       []byte(apiURL),
+		UserAgent: ctx.UserAgent(),
+	}
+	body, err := b.doRequestJson(b.defaultClient, reqParams)
+	if err != nil {
+		return nil, err
+	}
+
+	b.sugar.Debug("Content: ", string(body))
+
+	return body, nil
+}
+
+func (b *BiliroamingGo) doAuth(ctx *fasthttp.RequestCtx, accessKey string, clientType ClientType, area string, isForced bool) (bool, *userStatus) {
+	if token := b.getSessionToken(ctx); token != "" && !isForced {
+		return b.doSessionAuth(ctx, token)
+	}
+
+	if len(accessKey) == 0 {
+		if sessdata := getSessdata(ctx); sessdata != "" {
+			ok, status := b.checkAuth(ctx, cookieCacheKey(sessdata), func() (*userStatus, error) {
+				return b.isCookieAuth(ctx, sessdata, isForced)
+			})
+			if ok {
+				status.keyless = true
+				b.touchCredential(ctx, status.uid, "", sessdata, clientType)
+			}
+			return ok, status
+		}
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_NOT_LOGIN, MSG_ERROR_AUTH_NOT_LOGIN)
+		return false, nil
+	}
+
+	if len(accessKey) != 32 {
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_ACCESS_KEY, MSG_ERROR_AUTH_ACCESS_KEY)
+		return false, nil
+	}
+
+	ok, status := b.checkAuth(ctx, accessKey, func() (*userStatus, error) {
+		return b.isAuth(ctx, accessKey, clientType, isForced)
+	})
+	if ok {
+		b.touchCredential(ctx, status.uid, accessKey, "", clientType)
+	}
+	return ok, status
+}
+
+// checkAuth cached status by key or fetch by isAuth, then limiter and ban checks
+func (b *BiliroamingGo) checkAuth(ctx *fasthttp.RequestCtx, cacheKey string, isAuth func() (*userStatus, error)) (bool, *userStatus) {
+	key, ok := b.getKey(cacheKey)
+	if ok {
+		if !b.doCheckUidLimiter(ctx, key.uid) {
+			return false, nil
+		}
+		switch b.config.BlockType {
+		case BlockTypeEnabled:
+			if key.isBlacklist && !b.reportOnly(policyBlacklist, fmt.Sprintf("uid %d", key.uid)) {
+				writeErrorJSON(ctx, ERROR_CODE_AUTH_BLACKLIST, fmt.Sprintf(MSG_ERROR_AUTH_BLACKLIST, key.uid, key.banUntil.In(LOCATION_SHANGHAI).Format(TIME_FORMAT)))
+				return false, nil
+			}
+		case BlockTypeWhitelist:
+			if !key.isWhitelist && !b.reportOnly(policyWhitelist, fmt.Sprintf("uid %d", key.uid)) {
+				writeErrorJSON(ctx, ERROR_CODE_AUTH_WHITELIST, MSG_ERROR_AUTH_WHITELIST)
+				return false, nil
+			}
+		}
+		if !key.isLogin {
+			writeErrorJSON(ctx, ERROR_CODE_AUTH_NOT_LOGIN, MSG_ERROR_AUTH_NOT_LOGIN)
+			return false, nil
+		}
+		return key.isLogin, &userStatus{
+			isLogin:     key.isLogin,
+			isVip:       key.isVip,
+			isBlacklist: key.isBlacklist,
+			isWhitelist: key.isWhitelist,
+			uid:         key.uid,
+			banUntil:    key.banUntil,
+		}
+	}
+
+	status, err := isAuth()
+	if err != nil {
+		b.setKey(cacheKey, status)
+		if status.isLogin {
+			return true, status
+		}
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_NOT_LOGIN, MSG_ERROR_AUTH_NOT_LOGIN)
+		return false, nil
+	}
+
+	if status.isBlacklist {
+		// other keys of user may be cached before ban
+		b.evictUser(status.uid, database.InvalidationBanChange)
+	}
+	b.setKey(cacheKey, status)
+
+	switch b.config.BlockType {
+	case BlockTypeEnabled:
+		if status.isBlacklist && !b.reportOnly(policyBlacklist, fmt.Sprintf("uid %d", status.uid)) {
+			if b.sessionSecret != nil {
+				if err := b.revokeSessions(status.uid); err != nil {
+					b.sugar.Error(err)
+				}
+			}
+			writeErrorJSON(ctx, ERROR_CODE_AUTH_BLACKLIST, fmt.Sprintf(MSG_ERROR_AUTH_BLACKLIST, status.uid, status.banUntil.In(LOCATION_SHANGHAI).Format(TIME_FORMAT)))
+			return false, nil
+		}
+	case BlockTypeWhitelist:
+		if !status.isWhitelist && !b.reportOnly(policyWhitelist, fmt.Sprintf("uid %d", status.uid)) {
+			writeErrorJSON(ctx, ERROR_CODE_AUTH_WHITELIST, MSG_ERROR_AUTH_WHITELIST)
+			return false, nil
+		}
+	}
+
+	if !b.doCheckUidLimiter(ctx, status.uid) {
+		return false, nil
+	}
+
+	return true, status
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	cacheShardCount = 32
+	// cachePromoteInterval recently promoted entries are read without write lock
+	cachePromoteInterval = time.Second
+	// cacheEvictScan least recently used entries checked for eviction
+	cacheEvictScan = 8
+)
+
+type cacheEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt atomic.Int64
+	touchedAt atomic.Int64
+}
+
+type cacheShard[V any] struct {
+	mu    sync.RWMutex
+	items map[string]*list.Element
+	lru   *list.List
+}
+
+// shardedCache concurrent cache with sharded locks, LRU eviction and TTL checked on access,
+// sliding ttl extend expiry on every access
+type shardedCache[V any] struct {
+	name        string
+	shards      [cacheShardCount]*cacheShard[V]
+	maxPerShard int
+	ttl         time.Duration
+	sliding     bool
+	metrics     *metrics
+	// evictable entries allowed to be evicted when full, nil evict any,
+	// shard grows over max when no scanned entry is evictable
+	evictable func(V) bool
+
+	hits      *atomic.Int64
+	misses    *atomic.Int64
+	expired   *atomic.Int64
+	evictions *atomic.Int64
+}
+
+func newShardedCache[V any](name string, maxEntries int, ttl time.Duration, sliding bool, m *metrics) *shardedCache[V] {
+	maxPerShard := maxEntries / cacheShardCount
+	if maxPerShard <= 0 {
+		maxPerShard = 1
+	}
+	c := &shardedCache[V]{
+		name:        name,
+		maxPerShard: maxPerShard,
+		ttl:         ttl,
+		sliding:     sliding,
+		metrics:     m,
+	}
+	if m != nil {
+		c.hits = m.counter("biliroaming_cache_requests_total", "cache", name, "result", "hit")
+		c.misses = m.counter("biliroaming_cache_requests_total", "cache", name, "result", "miss")
+		c.expired = m.counter("biliroaming_cache_requests_total", "cache", name, "result", "expired")
+		c.evictions = m.counter("biliroaming_cache_evictions_total", "cache", name)
+	} else {
+		c.hits, c.misses, c.expired, c.evictions = &atomic.Int64{}, &atomic.Int64{}, &atomic.Int64{}, &atomic.Int64{}
+	}
+	for i := range c.shards {
+		c.shards[i] = &cacheShard[V]{
+			items: make(map[string]*list.Element),
+			lru:   list.New(),
+		}
+	}
+	return c
+}
+
+func (c *shardedCache[V]) getShard(key string) *cacheShard[V] {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%cacheShardCount]
+}
+
+// touch extend sliding ttl, move to front at most once per promote interval
+func (c *shardedCache[V]) touch(shard *cacheShard[V], elem *list.Element, now time.Time) {
+	entry := elem.Value.(*cacheEntry[V])
+	if c.sliding {
+		entry.expiresAt.Store(now.Add(c.ttl).UnixNano())
+	}
+	if now.UnixNano()-entry.touchedAt.Load() < int64(cachePromoteInterval) {
+		return
+	}
+	entry.touchedAt.Store(now.UnixNano())
+	shard.mu.Lock()
+	if shard.items[entry.key] == elem {
+		shard.lru.MoveToFront(elem)
+	}
+	shard.mu.Unlock()
+}
+
+func (c *shardedCache[V]) get(key string) (V, bool) {
+	var zero V
+	shard := c.getShard(key)
+	now := time.Now()
+
+	shard.mu.RLock()
+	elem, ok := shard.items[key]
+	shard.mu.RUnlock()
+	if !ok {
+		c.misses.Add(1)
+		return zero, false
+	}
+
+	entry := elem.Value.(*cacheEntry[V])
+	if now.UnixNano() > entry.expiresAt.Load() {
+		shard.mu.Lock()
+		if shard.items[key] == elem {
+			shard.lru.Remove(elem)
+			delete(shard.items, key)
+		}
+		shard.mu.Unlock()
+		c.expired.Add(1)
+		return zero, false
+	}
+	c.touch(shard, elem, now)
+	c.hits.Add(1)
+	return entry.value, true
+}
+
+// getOrCreate create value under shard lock when missing or expired
+func (c *shardedCache[V]) getOrCreate(key string, create func() V) V {
+	if value, ok := c.get(key); ok {
+		return value
+	}
+	shard := c.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if elem, ok := shard.items[key]; ok {
+		entry := elem.Value.(*cacheEntry[V])
+		if time.Now().UnixNano() <= entry.expiresAt.Load() {
+			return entry.value
+		}
+	}
+	value := create()
+	c.setLocked(shard, key, value)
+	return value
+}
+
+func (c *shardedCache[V]) set(key string, value V) {
+	shard := c.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	c.setLocked(shard, key, value)
+}
+
+func (c *shardedCache[V]) setLocked(shard *cacheShard[V], key string, value V) {
+	now := time.Now()
+	if elem, ok := shard.items[key]; ok {
+		shard.lru.Remove(elem)
+		delete(shard.items, key)
+	}
+	for len(shard.items) >= c.maxPerShard {
+		victim := c.victimLocked(shard)
+		if victim == nil {
+			break
+		}
+		shard.lru.Remove(victim)
+		delete(shard.items, victim.Value.(*cacheEntry[V]).key)
+		c.evictions.Add(1)
+	}
+	entry := &cacheEntry[V]{
+		key:   key,
+		value: value,
+	}
+	entry.expiresAt.Store(now.Add(c.ttl).UnixNano())
+	entry.touchedAt.Store(now.UnixNano())
+	shard.items[key] = shard.lru.PushFront(entry)
+}
+
+// victimLocked least recently used evictable entry
+func (c *shardedCache[V]) victimLocked(shard *cacheShard[V]) *list.Element {
+	elem := shard.lru.Back()
+	if c.evictable == nil {
+		return elem
+	}
+	for i := 0; elem != nil && i < cacheEvictScan; i++ {
+		if c.evictable(elem.Value.(*cacheEntry[V]).value) {
+			return elem
+		}
+		elem = elem.Prev()
+	}
+	return nil
+}
+
+func (c *shardedCache[V]) delete(key string) {
+	shard := c.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if elem, ok := shard.items[key]; ok {
+		shard.lru.Remove(elem)
+		delete(shard.items, key)
+	}
+}
+
+// deleteFunc delete entries matched by fn
+func (c *shardedCache[V]) deleteFunc(fn func(V) bool) int64 {
+	var count int64
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		for key, elem := range shard.items {
+			if fn(elem.Value.(*cacheEntry[V]).value) {
+				shard.lru.Remove(elem)
+				delete(shard.items, key)
+				count++
+			}
+		}
+		shard.mu.Unlock()
+	}
+	return count
+}
+
+func (c *shardedCache[V]) flush() {
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		shard.items = make(map[string]*list.Element)
+		shard.lru.Init()
+		shard.mu.Unlock()
+	}
+}
+
+// cleanup remove expired entries, update size gauge
+func (c *shardedCache[V]) cleanup() int64 {
+	now := time.Now().UnixNano()
+	var count, size int64
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		for key, elem := range shard.items {
+			if now > elem.Value.(*cacheEntry[V]).expiresAt.Load() {
+				shard.lru.Remove(elem)
+				delete(shard.items, key)
+				count++
+			}
+		}
+		size += int64(len(shard.items))
+		shard.mu.Unlock()
+	}
+	if c.metrics != nil {
+		c.metrics.set("biliroaming_cache_entries", float64(size), "cache", c.name)
+	}
+	return count
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sameShardKeys n keys hashed to the same shard
+func sameShardKeys[V any](c *shardedCache[V], n int) []string {
+	shard := c.getShard("0")
+	keys := []string{"0"}
+	for i := 1; len(keys) < n; i++ {
+		key := strconv.Itoa(i)
+		if c.getShard(key) == shard {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	c := newShardedCache[int]("test", 2*cacheShardCount, time.Minute, false, nil)
+	keys := sameShardKeys(c, 3)
+
+	c.set(keys[0], 0)
+	c.set(keys[1], 1)
+	// promote keys[0] as if read after promote interval
+	shard := c.getShard(keys[0])
+	shard.items[keys[0]].Value.(*cacheEntry[int]).touchedAt.Store(0)
+	if _, ok := c.get(keys[0]); !ok {
+		t.Fatal("keys[0] missing")
+	}
+
+	c.set(keys[2], 2)
+	if _, ok := c.get(keys[1]); ok {
+		t.Error("least recently used keys[1] not evicted")
+	}
+	for _, key := range []string{keys[0], keys[2]} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("%s evicted", key)
+		}
+	}
+	if n := c.evictions.Load(); n != 1 {
+		t.Errorf("evictions = %d, want 1", n)
+	}
+}
+
+func TestCachePerShardCap(t *testing.T) {
+	c := newShardedCache[int]("test", 4*cacheShardCount, time.Minute, false, nil)
+	for i := 0; i < 100*cacheShardCount; i++ {
+		c.set(strconv.Itoa(i), i)
+	}
+	for i, shard := range c.shards {
+		if n := len(shard.items); n > 4 {
+			t.Errorf("shard %d has %d entries, max 4", i, n)
+		}
+		if shard.lru.Len() != len(shard.items) {
+			t.Errorf("shard %d lru %d entries, map %d", i, shard.lru.Len(), len(shard.items))
+		}
+	}
+}
+
+func TestCacheTTLExpiry(t *testing.T) {
+	c := newShardedCache[int]("test", 100, 20*time.Millisecond, false, nil)
+	c.set("a", 1)
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("a missing before ttl")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := c.get("a"); ok {
+		t.Error("a not expired after ttl")
+	}
+	if n := c.expired.Load(); n != 1 {
+		t.Errorf("expired = %d, want 1", n)
+	}
+	if _, ok := c.getShard("a").items["a"]; ok {
+		t.Error("expired entry not removed on get")
+	}
+}
+
+func TestCacheSlidingTTL(t *testing.T) {
+	fixed := newShardedCache[int]("fixed", 100, 50*time.Millisecond, false, nil)
+	sliding := newShardedCache[int]("sliding", 100, 50*time.Millisecond, true, nil)
+	fixed.set("a", 1)
+	sliding.set("a", 1)
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		sliding.get("a")
+	}
+	if _, ok := sliding.get("a"); !ok {
+		t.Error("sliding entry expired while accessed")
+	}
+	if _, ok := fixed.get("a"); ok {
+		t.Error("fixed entry not expired")
+	}
+}
+
+func TestCacheConcurrentGetOrCreate(t *testing.T) {
+	c := newShardedCache[*atomic.Int64]("test", 100, time.Minute, false, nil)
+	var created atomic.Int64
+	var wg sync.WaitGroup
+	values := make([]*atomic.Int64, 64)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i] = c.getOrCreate("a", func() *atomic.Int64 {
+				created.Add(1)
+				return &atomic.Int64{}
+			})
+		}(i)
+	}
+	wg.Wait()
+	if n := created.Load(); n != 1 {
+		t.Errorf("created %d times, want 1", n)
+	}
+	for i, v := range values {
+		if v != values[0] {
+			t.Errorf("goroutine %d got another value", i)
+		}
+	}
+}
+
+func TestCacheMetricsCounters(t *testing.T) {
+	m := newMetrics()
+	c := newShardedCache[int]("test", 100, time.Minute, false, m)
+	c.set("a", 1)
+	c.get("a")
+	c.get("b")
+	if n := m.counter("biliroaming_cache_requests_total", "cache", "test", "result", "hit").Load(); n != 1 {
+		t.Errorf("hit = %d, want 1", n)
+	}
+	if n := m.counter("biliroaming_cache_requests_total", "cache", "test", "result", "miss").Load(); n != 1 {
+		t.Errorf("miss = %d, want 1", n)
+	}
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Config struct {
+	Debug bool `yaml:"debug"`
+	Port  int  `yaml:"port"`
+	IPV6  bool `yaml:"ipv6"`
+
+	TrustedProxies []string `yaml:"trustedProxies"`
+	RealIPHeader   string   `yaml:"realIPHeader"`
+
+	VipOnly bool `yaml:"vipOnly"`
+
+	BlacklistApiUrl string        `yaml:"blacklistApiUrl"`
+	BlockType       BlockTypeEnum `yaml:"blockType"`
+
+	RoamingMinVer int `yaml:"roamingMinVer"`
+
+	VersionPolicy VersionPolicy `yaml:"versionPolicy"`
+
+	AppKeys []AppKey `yaml:"appKeys"`
+
+	SignCheck struct {
+		Skew      time.Duration `yaml:"skew"`
+		Web       bool          `yaml:"web"`
+		MaxNonces int           `yaml:"maxNonces"`
+	} `yaml:"signCheck"`
+
+	DefaultArea string `yaml:"defaultArea"`
+
+	GeoIP struct {
+		CountryDatabase string            `yaml:"countryDatabase"`
+		AsnDatabase     string            `yaml:"asnDatabase"`
+		DefaultArea     map[string]string `yaml:"defaultArea"`
+	} `yaml:"geoIP"`
+
+	AccessRules map[string]*AccessRule `yaml:"accessRules"`
+
+	Routes map[string]bool `yaml:"routes"`
+
+	Policies map[string]PolicyMode `yaml:"policies"`
+
+	ThRedirect struct {
+		Aid int `yaml:"aid"`
+	} `yaml:"thRedirect"`
+
+	Limiter struct {
+		Limit      int `yaml:"limit"`
+		Burst      int `yaml:"burst"`
+		MaxEntries int `yaml:"maxEntries"`
+	} `yaml:"limiter"`
+
+	SearchLimiter struct {
+		Limit      int `yaml:"limit"`
+		Burst      int `yaml:"burst"`
+		MaxEntries int `yaml:"maxEntries"`
+	} `yaml:"searchLimiter"`
+
+	IpLimiter struct {
+		Limit      int `yaml:"limit"`
+		Burst      int `yaml:"burst"`
+		MaxEntries int `yaml:"maxEntries"`
+	} `yaml:"ipLimiter"`
+
+	CustomSearch struct {
+		Data    string `yaml:"data"`
+		WebData string `yaml:"webData"`
+	} `yaml:"customSearch"`
+
+	CustomSubtitle struct {
+		ApiUrl   string `yaml:"apiUrl"`
+		TeamName string `yaml:"teamName"`
+	} `yaml:"customSubtitle"`
+
+	SharedState struct {
+		Backend SharedStateBackend `yaml:"backend"`
+		Notify  bool               `yaml:"notify"`
+		// LocalTTL local auth cache in front of postgres
+		LocalTTL time.Duration `yaml:"localTTL"`
+	} `yaml:"sharedState"`
+
+	AccessKeyHash struct {
+		Secret     string `yaml:"secret"`
+		SecretFile string `yaml:"secretFile"`
+	} `yaml:"accessKeyHash"`
+
+	SessionToken struct {
+		Enabled    bool          `yaml:"enabled"`
+		Secret     string        `yaml:"secret"`
+		SecretFile string        `yaml:"secretFile"`
+		TTL        time.Duration `yaml:"ttl"`
+	} `yaml:"sessionToken"`
+
+	Admin struct {
+		Token     string `yaml:"token"`
+		TokenFile string `yaml:"tokenFile"`
+	} `yaml:"admin"`
+
+	Jobs map[string]*JobConfig `yaml:"jobs"`
+
+	VipRefresh struct {
+		Enabled      bool          `yaml:"enabled"`
+		Window       time.Duration `yaml:"window"`
+		ActiveWithin time.Duration `yaml:"activeWithin"`
+		BatchSize    int           `yaml:"batchSize"`
+	} `yaml:"vipRefresh"`
+
+	Retention struct {
+		InactiveDays int           `yaml:"inactiveDays"`
+		Mode         RetentionMode `yaml:"mode"`
+		BatchSize    int           `yaml:"batchSize"`
+	} `yaml:"retention"`
+
+	Cache struct {
+		AccessKey  time.Duration `yaml:"accessKey"`
+		User       time.Duration `yaml:"user"`
+		PlayUrl    time.Duration `yaml:"playUrl"`
+		THSeason   time.Duration `yaml:"thSeason"`
+		THSubtitle time.Duration `yaml:"thSubtitle"`
+
+		AccessKeyMaxEntries int `yaml:"accessKeyMaxEntries"`
+	} `yaml:"cache"`
+
+	Proxy struct {
+		CN      string `yaml:"cn"`
+		HK      string `yaml:"hk"`
+		TW      string `yaml:"tw"`
+		TH      string `yaml:"th"`
+		Default string `yaml:"default"`
+	} `yaml:"proxy"`
+
+	Reverse struct {
+		CN string `yaml:"cn"`
+		HK string `yaml:"hk"`
+		TW string `yaml:"tw"`
+		TH string `yaml:"th"`
+	} `yaml:"reverse"`
+
+	ReverseSearch struct {
+		CN string `yaml:"cn"`
+		HK string `yaml:"hk"`
+		TW string `yaml:"tw"`
+		TH string `yaml:"th"`
+	} `yaml:"reverseSearch"`
+
+	ReverseWebSearch struct {
+		CN string `yaml:"cn"`
+		HK string `yaml:"hk"`
+		TW string `yaml:"tw"`
+	} `yaml:"reverseWebSearch"`
+
+	Auth struct {
+		CN bool `yaml:"cn"`
+		HK bool `yaml:"hk"`
+		TW bool `yaml:"tw"`
+		TH bool `yaml:"th"`
+	} `yaml:"auth"`
+
+	PostgreSQL struct {
+		Host         string `yaml:"host"`
+		User         string `yaml:"user"`
+		Password     string `yaml:"password"`
+		PasswordFile string `yaml:"passwordFile"`
+		DBName       string `yaml:"dbName"`
+		Port         int    `yaml:"port"`
+	} `yaml:"postgreSQL"`
+}
+
+// LoadConfig read yaml config file
+func LoadConfig(configPath string) (*Config, error) {
+	config := &Config{}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// String config with secrets masked for logging
+func (c *Config) String() string {
+	masked := *c
+	if masked.PostgreSQL.Password != "" {
+		masked.PostgreSQL.Password = redactedValue
+	}
+	if masked.AccessKeyHash.Secret != "" {
+		masked.AccessKeyHash.Secret = redactedValue
+	}
+	if masked.Admin.Token != "" {
+		masked.Admin.Token = redactedValue
+	}
+	if masked.SessionToken.Secret != "" {
+		masked.SessionToken.Secret = redactedValue
+	}
+	masked.Proxy.CN = redactURL(masked.Proxy.CN)
+	masked.Proxy.HK = redactURL(masked.Proxy.HK)
+	masked.Proxy.TW = redactURL(masked.Proxy.TW)
+	masked.Proxy.TH = redactURL(masked.Proxy.TH)
+	masked.Proxy.Default = redactURL(masked.Proxy.Default)
+	data, err := yaml.Marshal(&masked)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// redactURL mask user info of proxy url, http proxy may be user:pass@host:port without scheme
+func redactURL(rawURL string) string {
+	if !strings.Contains(rawURL, "://") {
+		if i := strings.LastIndex(rawURL, "@"); i >= 0 {
+			return redactedValue + rawURL[i:]
+		}
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = url.User(redactedValue)
+	return u.String()
+}
+
+func (c *Config) saveConfig(configPath string) error {
+	data, err := yaml.Marshal(&c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return err
+	}
+
+	return nil
+}
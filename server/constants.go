@@ -1,9 +1,10 @@
-package main
+package server
 
 import "time"
 
 const (
 	ERROR_CODE_GEO_RESTRICED     = 403
+	ERROR_CODE_IP_RESTRICTED     = 403
 	ERROR_CODE_INTERNAL_SERVER   = 500
 	ERROR_CODE_PARAMETERS        = 400
 	ERROR_CODE_TOO_MANY_REQUESTS = 429
@@ -14,15 +15,23 @@ const (
 	ERROR_CODE_AUTH_BLACKLIST  = 403
 	ERROR_CODE_AUTH_NOT_LOGIN  = 401
 	ERROR_CODE_AUTH_WHITELIST  = 403
+	ERROR_CODE_AUTH_SESSION    = 401
 
-	ERROR_CODE_HEADER_MIN_VERSION = 400
-	ERROR_CODE_HEADER_WRONG       = 400
+	ERROR_CODE_HEADER_MIN_VERSION     = 400
+	ERROR_CODE_HEADER_WRONG           = 400
+	ERROR_CODE_HEADER_BLOCKED_VERSION = 400
+	ERROR_CODE_HEADER_MISSING         = 403
 
 	ERROR_CODE_MISSING_AREA     = 400
 	ERROR_CODE_MISSING_TYPE     = 400
 	ERROR_CODE_MISSING_KEYWORD  = 400
 	ERROR_CODE_MISSING_COOKIE   = 400
 	ERROR_CODE_MISSING_SS_OR_EP = 400
+
+	ERROR_CODE_AUTH_ADMIN         = 401
+	ERROR_CODE_JOB_NOT_FOUND      = 404
+	ERROR_CODE_JOB_NOT_LEADER     = 409
+	ERROR_CODE_METHOD_NOT_ALLOWED = 405
 )
 
 var LOCATION_SHANGHAI = time.FixedZone("Asia/Shanghai", 8)
@@ -31,23 +40,35 @@ const (
 	TIME_FORMAT = "2006-01-02 15:04:05"
 
 	MSG_ERROR_GEO_RESTRICTED    = "地区限制！"
+	MSG_ERROR_IP_RESTRICTED     = "IP 访问受限！"
 	MSG_ERROR_INTERNAL_SERVER   = "解析服务器错误！"
 	MSG_ERROR_PARAMETERS        = "参数错误！"
 	MSG_ERROR_TOO_MANY_REQUESTS = "请求过于频繁！"
 	MSG_ERROR_VIP_ONLY          = "仅限大会员用户！"
 	MSG_ERROR_VIP_STATUS        = "大会员状态异常！"
+	MSG_ERROR_SIGN_REPLAYED     = "重复的请求！"
+
+	MSG_ERROR_TOO_MANY_REQUESTS_RETRY = "请求过于频繁！请 %d 秒后重试"
 
 	MSG_ERROR_AUTH_ACCESS_KEY = "access_key 错误或模块问题！"
 	MSG_ERROR_AUTH_BLACKLIST  = "黑名单\nUID: %d\n解除时间: %s"
 	MSG_ERROR_AUTH_NOT_LOGIN  = "账号未登录！"
 	MSG_ERROR_AUTH_WHITELIST  = "本解析服务器仅限白名单用户使用！"
+	MSG_ERROR_AUTH_SESSION    = "会话已失效，请重新获取！"
 
-	MSG_ERROR_HEADER_MIN_VERSION = "模块版本过低！"
-	MSG_ERROR_HEADER_WRONG       = "错误的请求头！"
+	MSG_ERROR_HEADER_MIN_VERSION     = "模块版本过低！"
+	MSG_ERROR_HEADER_WRONG           = "错误的请求头！"
+	MSG_ERROR_HEADER_BLOCKED_VERSION = "模块版本存在已知问题，请更新！"
+	MSG_ERROR_HEADER_MISSING         = "本解析服务器仅限哔哩漫游使用！"
 
 	MSG_ERROR_MISSING_AREA     = "缺少 area 参数！"
 	MSG_ERROR_MISSING_TYPE     = "缺少 type 参数！"
 	MSG_ERROR_MISSING_KEYWORD  = "缺少 keyword 参数！"
 	MSG_ERROR_MISSING_COOKIE   = "缺少 cookie！"
 	MSG_ERROR_MISSING_SS_OR_EP = "缺少 season_id 或 ep_id 参数！"
+
+	MSG_ERROR_AUTH_ADMIN         = "管理 token 错误！"
+	MSG_ERROR_JOB_NOT_FOUND      = "任务不存在！"
+	MSG_ERROR_JOB_NOT_LEADER     = "非主节点，任务仅在主节点运行！"
+	MSG_ERROR_METHOD_NOT_ALLOWED = "请求方法错误！"
 )
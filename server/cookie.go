@@ -0,0 +1,119 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/entity/web"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+)
+
+const cookieSessdata = "SESSDATA"
+
+func getSessdata(ctx *fasthttp.RequestCtx) string {
+	return string(ctx.Request.Header.Cookie(cookieSessdata))
+}
+
+// cookieCacheKey auth cache key of SESSDATA, never collide with access key
+func cookieCacheKey(sessdata string) string {
+	return "sessdata:" + sessdata
+}
+
+func (b *BiliroamingGo) getNav(ctx *fasthttp.RequestCtx, sessdata string) (*web.NavResult, error) {
+	reqParams := &HttpRequestParams{
+		Method:    []byte(fasthttp.MethodGet),
+		Url:       []byte("https://api.bilibili.com/x/web-interface/nav"),
+		UserAgent: ctx.UserAgent(),
+		Cookie: []HttpCookiesParams{
+			{Key: []byte(cookieSessdata), Value: []byte(sessdata)},
+		},
+	}
+	body, err := b.doRequestJson(b.defaultClient, reqParams)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &web.NavResult{}
+	if err := easyjson.Unmarshal(body, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// getCookieAccountInfo account of SESSDATA, invalid cookie is removed
+func (b *BiliroamingGo) getCookieAccountInfo(ctx *fasthttp.RequestCtx, sessdata string) (*accountInfo, error) {
+	data, err := b.getNav(ctx, sessdata)
+	if err != nil {
+		return nil, err
+	}
+	if data.Code != 0 || !data.Data.IsLogin {
+		if err := b.db.DeleteCookieSession(sessdata); err != nil {
+			b.sugar.Error(err)
+		}
+		return nil, errors.New(data.Message)
+	}
+	account := &accountInfo{
+		uid:    data.Data.Mid,
+		name:   data.Data.Uname,
+		vipDue: time.Unix(0, 0),
+	}
+	if data.Data.VipStatus == 1 {
+		account.vipDue = time.UnixMilli(data.Data.VipDueDate)
+	}
+	return account, nil
+}
+
+// isCookieAuth same as isAuth with web cookie, share users table
+func (b *BiliroamingGo) isCookieAuth(ctx *fasthttp.RequestCtx, sessdata string, isForced bool) (*userStatus, error) {
+	userStatus := &userStatus{
+		uid: -1,
+	}
+
+	userData, err := b.db.GetUserFromCookie(sessdata)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		b.sugar.Error("GetUserFromCookie error ", err)
+		return userStatus, err
+	} else if err == nil && !isForced && userData.UpdatedAt.After(time.Now().Add(-b.config.Cache.User)) {
+		b.sugar.Debugf("Get vip status from cache: %d %s", userData.UID, userData.VipDueDate)
+
+		userStatus.uid = userData.UID
+		userStatus.isLogin = true
+		userStatus.isVip = userData.VipDueDate.After(time.Now())
+
+		if err := b.fillBWlist(ctx, userStatus); err != nil {
+			return userStatus, err
+		}
+		return userStatus, nil
+	}
+
+	account, err := b.getCookieAccountInfo(ctx, sessdata)
+	if err != nil {
+		return userStatus, err
+	}
+
+	userStatus.uid = account.uid
+	userStatus.isLogin = true
+
+	if err := b.db.InsertOrUpdateUser(account.uid, account.name, account.vipDue); err != nil {
+		return userStatus, err
+	}
+
+	if isForced || (userData != nil && !userData.VipDueDate.Equal(account.vipDue)) {
+		b.evictUser(account.uid, database.InvalidationUserReauth)
+	}
+
+	if err := b.db.InsertOrUpdateCookieSession(sessdata, account.uid); err != nil {
+		return userStatus, err
+	}
+
+	userStatus.isVip = account.vipDue.After(time.Now())
+
+	if err := b.fillBWlist(ctx, userStatus); err != nil {
+		return userStatus, err
+	}
+
+	return userStatus, nil
+}
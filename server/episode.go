@@ -1,4 +1,4 @@
-package main
+package server
 
 import (
 	"errors"
@@ -12,14 +12,7 @@ import (
 )
 
 func (b *BiliroamingGo) handleBstarEpisode(ctx *fasthttp.RequestCtx) {
-	if !b.checkRoamingVer(ctx) {
-		return
-	}
-
-	queryArgs := ctx.URI().QueryArgs()
-	args := b.processArgs(queryArgs)
-
-	args.area = "th"
+	args := b.getArgs(ctx)
 
 	// 验证 epId
 	if args.epId == 0 {
@@ -47,7 +40,7 @@ func (b *BiliroamingGo) handleBstarEpisode(ctx *fasthttp.RequestCtx) {
 	v.Set("s_locale", "zh_SG")
 	v.Set("ep_id", strconv.FormatInt(args.epId, 10))
 
-	params, err := SignParams(v, ClientTypeBstarA)
+	params, err := b.SignParams(v, ClientTypeBstarA)
 	if err != nil {
 		b.processError(ctx, err)
 		return
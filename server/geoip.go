@@ -0,0 +1,213 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/valyala/fasthttp"
+)
+
+const userValueGeoInfo = "geoInfo"
+
+// AccessRule IP/GeoIP 访问规则
+type AccessRule struct {
+	AllowCidrs     []string `yaml:"allowCidrs"`
+	DenyCidrs      []string `yaml:"denyCidrs"`
+	AllowCountries []string `yaml:"allowCountries"`
+	DenyCountries  []string `yaml:"denyCountries"`
+	AllowAsns      []uint   `yaml:"allowAsns"`
+	DenyAsns       []uint   `yaml:"denyAsns"`
+}
+
+type accessRule struct {
+	allowNets      []*net.IPNet
+	denyNets       []*net.IPNet
+	allowCountries map[string]struct{}
+	denyCountries  map[string]struct{}
+	allowAsns      map[uint]struct{}
+	denyAsns       map[uint]struct{}
+}
+
+type geoInfo struct {
+	country string
+	asn     uint
+}
+
+type geoIP struct {
+	country *geoip2.Reader
+	asn     *geoip2.Reader
+}
+
+func newGeoIP(countryPath, asnPath string) (*geoIP, error) {
+	g := &geoIP{}
+	if countryPath != "" {
+		reader, err := geoip2.Open(countryPath)
+		if err != nil {
+			return nil, err
+		}
+		g.country = reader
+	}
+	if asnPath != "" {
+		reader, err := geoip2.Open(asnPath)
+		if err != nil {
+			g.Close()
+			return nil, err
+		}
+		g.asn = reader
+	}
+	return g, nil
+}
+
+func (g *geoIP) lookup(ip net.IP) *geoInfo {
+	info := &geoInfo{}
+	if g == nil || ip == nil {
+		return info
+	}
+	if g.country != nil {
+		if record, err := g.country.Country(ip); err == nil {
+			info.country = record.Country.IsoCode
+		}
+	}
+	if g.asn != nil {
+		if record, err := g.asn.ASN(ip); err == nil {
+			info.asn = record.AutonomousSystemNumber
+		}
+	}
+	return info
+}
+
+func (g *geoIP) Close() {
+	if g == nil {
+		return
+	}
+	if g.country != nil {
+		g.country.Close()
+	}
+	if g.asn != nil {
+		g.asn.Close()
+	}
+}
+
+func newAccessRule(rule *AccessRule) (*accessRule, error) {
+	allowNets, err := parseCIDRs(rule.AllowCidrs)
+	if err != nil {
+		return nil, err
+	}
+	denyNets, err := parseCIDRs(rule.DenyCidrs)
+	if err != nil {
+		return nil, err
+	}
+	r := &accessRule{
+		allowNets:      allowNets,
+		denyNets:       denyNets,
+		allowCountries: make(map[string]struct{}),
+		denyCountries:  make(map[string]struct{}),
+		allowAsns:      make(map[uint]struct{}),
+		denyAsns:       make(map[uint]struct{}),
+	}
+	for _, country := range rule.AllowCountries {
+		r.allowCountries[strings.ToUpper(country)] = struct{}{}
+	}
+	for _, country := range rule.DenyCountries {
+		r.denyCountries[strings.ToUpper(country)] = struct{}{}
+	}
+	for _, asn := range rule.AllowAsns {
+		r.allowAsns[asn] = struct{}{}
+	}
+	for _, asn := range rule.DenyAsns {
+		r.denyAsns[asn] = struct{}{}
+	}
+	return r, nil
+}
+
+func (r *accessRule) hasAllowList() bool {
+	return len(r.allowNets) > 0 || len(r.allowCountries) > 0 || len(r.allowAsns) > 0
+}
+
+// isAllowed deny 优先，存在 allow 列表时必须命中其中之一
+func (r *accessRule) isAllowed(ip net.IP, info *geoInfo) bool {
+	if containsIP(r.denyNets, ip) {
+		return false
+	}
+	if _, ok := r.denyCountries[info.country]; ok && info.country != "" {
+		return false
+	}
+	if _, ok := r.denyAsns[info.asn]; ok && info.asn != 0 {
+		return false
+	}
+
+	if !r.hasAllowList() {
+		return true
+	}
+	if containsIP(r.allowNets, ip) {
+		return true
+	}
+	if _, ok := r.allowCountries[info.country]; ok && info.country != "" {
+		return true
+	}
+	if _, ok := r.allowAsns[info.asn]; ok && info.asn != 0 {
+		return true
+	}
+	return false
+}
+
+func (b *BiliroamingGo) initAccessRules(c *Config) error {
+	geo, err := newGeoIP(c.GeoIP.CountryDatabase, c.GeoIP.AsnDatabase)
+	if err != nil {
+		return err
+	}
+	b.geoIP = geo
+
+	b.accessRules = make(map[string]*accessRule, len(c.AccessRules))
+	for name, rule := range c.AccessRules {
+		r, err := newAccessRule(rule)
+		if err != nil {
+			return err
+		}
+		b.accessRules[name] = r
+	}
+	return nil
+}
+
+// getGeoInfo lookup once per request
+func (b *BiliroamingGo) getGeoInfo(ctx *fasthttp.RequestCtx) *geoInfo {
+	if info, ok := ctx.UserValue(userValueGeoInfo).(*geoInfo); ok {
+		return info
+	}
+	info := b.geoIP.lookup(getClientIP(ctx))
+	ctx.SetUserValue(userValueGeoInfo, info)
+	return info
+}
+
+// checkAccessRule 按路由组检查访问规则，未设置则使用 default
+func (b *BiliroamingGo) checkAccessRule(ctx *fasthttp.RequestCtx, name string) bool {
+	rule, ok := b.accessRules[name]
+	if !ok {
+		name = "default"
+		rule, ok = b.accessRules[name]
+		if !ok {
+			return true
+		}
+	}
+	ip := getClientIP(ctx)
+	info := b.getGeoInfo(ctx)
+	if !rule.isAllowed(ip, info) && !b.reportOnly(accessRulePolicy(name), fmt.Sprintf("%s by rule %s", ip, name)) {
+		b.sugar.Debugf("Access denied by rule %s: %s country %s asn %d", name, ip, info.country, info.asn)
+		writeErrorJSON(ctx, ERROR_CODE_IP_RESTRICTED, MSG_ERROR_IP_RESTRICTED)
+		return false
+	}
+	return true
+}
+
+// getDefaultArea 根据 IP 所属国家选择默认 area
+func (b *BiliroamingGo) getDefaultArea(ctx *fasthttp.RequestCtx) string {
+	if len(b.config.GeoIP.DefaultArea) > 0 {
+		info := b.getGeoInfo(ctx)
+		if area, ok := b.config.GeoIP.DefaultArea[info.country]; ok && area != "" {
+			return area
+		}
+	}
+	return b.config.DefaultArea
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestAccessRuleIsAllowed(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rule AccessRule
+		ip   string
+		info geoInfo
+		want bool
+	}{
+		{"empty rule", AccessRule{}, "1.1.1.1", geoInfo{}, true},
+		{"deny cidr", AccessRule{DenyCidrs: []string{"1.1.1.0/24"}}, "1.1.1.1", geoInfo{}, false},
+		{"deny cidr miss", AccessRule{DenyCidrs: []string{"1.1.1.0/24"}}, "1.1.2.1", geoInfo{}, true},
+		{"deny country", AccessRule{DenyCountries: []string{"us"}}, "1.1.1.1", geoInfo{country: "US"}, false},
+		{"deny asn", AccessRule{DenyAsns: []uint{16509}}, "1.1.1.1", geoInfo{asn: 16509}, false},
+		{"unknown geo not denied", AccessRule{DenyCountries: []string{"US"}, DenyAsns: []uint{16509}}, "1.1.1.1", geoInfo{}, true},
+		{"allow cidr hit", AccessRule{AllowCidrs: []string{"10.0.0.0/8"}}, "10.1.1.1", geoInfo{}, true},
+		{"allow cidr miss", AccessRule{AllowCidrs: []string{"10.0.0.0/8"}}, "1.1.1.1", geoInfo{}, false},
+		{"allow country hit", AccessRule{AllowCountries: []string{"HK"}}, "1.1.1.1", geoInfo{country: "HK"}, true},
+		{"allow country unknown", AccessRule{AllowCountries: []string{"HK"}}, "1.1.1.1", geoInfo{}, false},
+		{"allow asn hit", AccessRule{AllowAsns: []uint{4760}}, "1.1.1.1", geoInfo{asn: 4760}, true},
+		{"deny wins over allow", AccessRule{AllowCountries: []string{"HK"}, DenyCidrs: []string{"1.1.1.1"}}, "1.1.1.1", geoInfo{country: "HK"}, false},
+		{"any allow list matches", AccessRule{AllowCidrs: []string{"10.0.0.0/8"}, AllowAsns: []uint{4760}}, "1.1.1.1", geoInfo{asn: 4760}, true},
+	} {
+		rule, err := newAccessRule(&tc.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := rule.isAllowed(net.ParseIP(tc.ip), &tc.info); got != tc.want {
+			t.Errorf("%s: allowed = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNewAccessRuleInvalidCIDR(t *testing.T) {
+	if _, err := newAccessRule(&AccessRule{DenyCidrs: []string{"not an ip"}}); err == nil {
+		t.Error("invalid deny cidr accepted")
+	}
+}
+
+func TestCheckAccessRuleGroup(t *testing.T) {
+	c := &Config{
+		AccessRules: map[string]*AccessRule{
+			"default": {},
+			"search":  {DenyCidrs: []string{"203.0.113.0/24"}},
+		},
+	}
+	b := newTestBiliroaming(t, c)
+	if err := b.initAccessRules(c); err != nil {
+		t.Fatal(err)
+	}
+
+	newCtx := func() *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Init(&fasthttp.Request{}, &net.TCPAddr{IP: net.ParseIP("203.0.113.5")}, nil)
+		return ctx
+	}
+	if !b.checkAccessRule(newCtx(), "playurl") {
+		t.Error("default rule rejected")
+	}
+	if b.checkAccessRule(newCtx(), "search") {
+		t.Error("search rule not applied")
+	}
+
+	c.Policies = map[string]PolicyMode{accessRulePolicy("search"): PolicyModeReport}
+	if !b.checkAccessRule(newCtx(), "search") {
+		t.Error("report mode of search rule rejected")
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// newTestBiliroaming memory backends without database and upstream clients
+func newTestBiliroaming(t *testing.T, c *Config) *BiliroamingGo {
+	t.Helper()
+	if c == nil {
+		c = &Config{}
+	}
+	logger := zap.NewNop()
+	b := &BiliroamingGo{
+		config:             c,
+		logger:             logger,
+		sugar:              logger.Sugar(),
+		metrics:            newMetrics(),
+		sessionRevocations: newSessionRevocations(),
+		recentCredentials:  newRecentCredentials(),
+	}
+	b.areas = newAreaCaches(b.metrics)
+	appKeys, err := newAppKeyRegistry(c.AppKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.appKeys = appKeys
+	if err := b.initSharedState(c); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
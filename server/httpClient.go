@@ -1,10 +1,9 @@
-package main
+package server
 
 import (
 	"bytes"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -65,10 +64,10 @@ func (b *BiliroamingGo) newClient(proxy string) *fasthttp.Client {
 	var dialFunc fasthttp.DialFunc
 	switch {
 	case strings.HasPrefix(proxy, "socks5://"), strings.HasPrefix(proxy, "socks5h://"):
-		b.sugar.Debug("New socks proxy client: ", proxy)
+		b.sugar.Debug("New socks proxy client: ", redactURL(proxy))
 		dialFunc = fasthttpproxy.FasthttpSocksDialer(proxy)
 	case proxy != "":
-		b.sugar.Debug("New http proxy client: ", proxy)
+		b.sugar.Debug("New http proxy client: ", redactURL(proxy))
 		dialFunc = fasthttpproxy.FasthttpHTTPDialer(proxy)
 	case proxy == "":
 		b.sugar.Debug("New normal client")
@@ -148,6 +147,7 @@ func setDefaultHeaders(ctx *fasthttp.RequestCtx) {
 }
 
 func writeErrorJSON(ctx *fasthttp.RequestCtx, code int, msg string) {
+	ctx.SetUserValue(userValueFailed, true)
 	setDefaultHeaders(ctx)
 	resp := &entity.SimpleResponse{
 		Code:    code,
@@ -176,46 +176,31 @@ func writeHealthJSON(ctx *fasthttp.RequestCtx, health *entity.Health) {
 	ctx.Write(respData)
 }
 
-func getClientPlatform(ctx *fasthttp.RequestCtx, appkey string) ClientType {
+func writeJSON(ctx *fasthttp.RequestCtx, v easyjson.Marshaler) {
+	setDefaultHeaders(ctx)
+	respData, err := easyjson.Marshal(v)
+	if err != nil {
+		ctx.Write([]byte(`{"code":500,"message":"解析服务器发送错误"}`))
+		return
+	}
+	ctx.Write(respData)
+}
+
+func (b *BiliroamingGo) getClientPlatform(ctx *fasthttp.RequestCtx, appkey string) ClientType {
 	platform := string(ctx.Request.Header.PeekBytes([]byte("platform-from-biliroaming")))
 	if platform == "" && appkey == "" {
 		return ClientTypeIphone
 	}
 	clientType := ClientType(platform)
-	if clientType.IsValid() {
+	if b.appKeys.isValid(clientType) {
 		return clientType
 	}
 	if appkey != "" {
-		return getClientTypeFromAppkey(appkey)
+		return b.appKeys.clientType(appkey)
 	}
 	return ClientTypeUnknown
 }
 
-func (b *BiliroamingGo) checkRoamingVer(ctx *fasthttp.RequestCtx) bool {
-	versionCode := ctx.Request.Header.PeekBytes([]byte("build"))
-	versionName := ctx.Request.Header.PeekBytes([]byte("x-from-biliroaming"))
-
-	if len(versionCode) == 0 && len(versionName) == 0 {
-		return true
-	}
-
-	if len(versionCode) > 0 && len(versionName) > 0 {
-		build, err := strconv.Atoi(string(versionCode))
-		if err != nil {
-			writeErrorJSON(ctx, ERROR_CODE_HEADER_WRONG, MSG_ERROR_HEADER_WRONG)
-			return false
-		}
-		if build < b.config.RoamingMinVer {
-			writeErrorJSON(ctx, ERROR_CODE_HEADER_MIN_VERSION, MSG_ERROR_HEADER_MIN_VERSION)
-			return false
-		}
-		return true
-	}
-
-	writeErrorJSON(ctx, ERROR_CODE_HEADER_WRONG, MSG_ERROR_HEADER_WRONG)
-	return false
-}
-
 func (b *BiliroamingGo) doRequest(client *fasthttp.Client, params *HttpRequestParams) ([]byte, error) {
 	if params == nil {
 		return nil, errors.New("params is nil")
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+)
+
+func (b *BiliroamingGo) initInvalidation() error {
+	if !b.config.SharedState.Notify {
+		return nil
+	}
+	listener, err := b.db.ListenInvalidation(b.handleInvalidation, func(err error) {
+		b.sugar.Error("Invalidation listener: ", err)
+	})
+	if err != nil {
+		return err
+	}
+	b.invalidationListener = listener
+	b.sugar.Infof("Listening invalidation events as instance %s", b.db.InstanceID())
+	// config may be changed by this deployment, drop results cached by others with old config
+	b.publishInvalidation(&database.InvalidationEvent{Type: database.InvalidationConfigChange})
+	return nil
+}
+
+func (b *BiliroamingGo) publishInvalidation(event *database.InvalidationEvent) {
+	if !b.config.SharedState.Notify {
+		return
+	}
+	if err := b.db.PublishInvalidation(event); err != nil {
+		b.sugar.Error(err)
+	}
+}
+
+// handleInvalidation evict local entries by events from other instances
+func (b *BiliroamingGo) handleInvalidation(event *database.InvalidationEvent) {
+	if event == nil {
+		b.sugar.Warn("Invalidation listener reconnected, flush local caches")
+		b.authCache.flush()
+		b.areas.flush()
+		if b.sessionSecret != nil {
+			if err := b.syncSessionRevocations(); err != nil {
+				b.sugar.Error(err)
+			}
+		}
+		return
+	}
+
+	b.sugar.Debugf("Invalidation event %s from %s", event.Type, event.Origin)
+
+	switch event.Type {
+	case database.InvalidationUserReauth, database.InvalidationBanChange:
+		b.authCache.deleteByUID(event.UID)
+	case database.InvalidationUserForget:
+		b.authCache.deleteByUID(event.UID)
+		b.recentCredentials.remove(event.UID)
+	case database.InvalidationSessionRevoke:
+		b.reloadSessionRevocation(event.UID)
+	case database.InvalidationEpisodeCache:
+		b.areas.episodes.delete(strconv.FormatInt(event.EpisodeID, 10))
+	case database.InvalidationSeasonCache:
+		b.areas.seasons.delete(strconv.FormatInt(event.SeasonID, 10))
+	case database.InvalidationConfigChange:
+		b.authCache.flush()
+		b.areas.flush()
+	default:
+		b.sugar.Warnf("Unknown invalidation event %s", event.Type)
+	}
+}
+
+// evictUser evict cached auth state of user on all instances
+func (b *BiliroamingGo) evictUser(uid int64, eventType database.InvalidationType) {
+	b.authCache.deleteByUID(uid)
+	b.publishInvalidation(&database.InvalidationEvent{
+		Type: eventType,
+		UID:  uid,
+	})
+}
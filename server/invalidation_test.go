@@ -0,0 +1,31 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/models"
+)
+
+func TestHandleInvalidation(t *testing.T) {
+	b := newTestBiliroaming(t, &Config{})
+	b.authCache.set("key", &accessKey{uid: 1})
+	b.areas.episodes.set("1", &models.EpisodeAreaCach{})
+	b.areas.seasons.set("2", &models.SeasonAreaCach{})
+
+	b.handleInvalidation(&database.InvalidationEvent{Type: database.InvalidationEpisodeCache, EpisodeID: 1})
+	if _, ok := b.areas.episodes.get("1"); ok {
+		t.Error("episode area cache not evicted")
+	}
+	if _, ok := b.areas.seasons.get("2"); !ok {
+		t.Error("season area cache evicted by episode event")
+	}
+
+	b.handleInvalidation(&database.InvalidationEvent{Type: database.InvalidationConfigChange})
+	if _, ok := b.authCache.get("key"); ok {
+		t.Error("auth cache not flushed on config change")
+	}
+	if _, ok := b.areas.seasons.get("2"); ok {
+		t.Error("season area cache not flushed on config change")
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import "time"
+
+const (
+	defaultJobInterval = 5 * time.Minute
+	defaultJobJitter   = 30 * time.Second
+)
+
+// registerJobs built-in jobs, name is the key in config jobs
+func (b *BiliroamingGo) registerJobs() {
+	s := b.scheduler
+
+	s.register("retention", b.config.Retention.InactiveDays > 0, true, time.Hour, 5*time.Minute, b.runRetention)
+	s.register("cleanupPlayUrl", true, true, defaultJobInterval, defaultJobJitter, func() (int64, error) {
+		return b.db.CleanupPlayURLCache(b.config.Cache.PlayUrl)
+	})
+	s.register("cleanupTHSeason", true, true, defaultJobInterval, defaultJobJitter, func() (int64, error) {
+		aff, err := b.db.CleanupTHSeasonCache(b.config.Cache.THSeason)
+		if err != nil {
+			return aff, err
+		}
+		aff2, err := b.db.CleanupTHSeason2Cache(b.config.Cache.THSeason)
+		return aff + aff2, err
+	})
+	s.register("cleanupTHSubtitle", true, true, defaultJobInterval, defaultJobJitter, func() (int64, error) {
+		return b.db.CleanupTHSubtitleCache(b.config.Cache.THSubtitle)
+	})
+
+	if b.config.SharedState.Backend == SharedStatePostgres {
+		s.register("cleanupSharedState", true, true, defaultJobInterval, defaultJobJitter, func() (int64, error) {
+			aff, err := b.db.CleanupAuthCaches(authCacheDuration)
+			if err != nil {
+				return aff, err
+			}
+			aff2, err := b.db.CleanupRateLimits(authCacheDuration)
+			aff += aff2
+			if err != nil {
+				return aff, err
+			}
+			aff3, err := b.db.CleanupNonces()
+			return aff + aff3, err
+		})
+	}
+
+	if b.sessionSecret != nil {
+		s.register("syncSessionRevocations", true, false, time.Minute, 10*time.Second, func() (int64, error) {
+			return 0, b.syncSessionRevocations()
+		})
+		s.register("cleanupSessionRevocations", true, true, time.Hour, 5*time.Minute, func() (int64, error) {
+			return b.db.CleanupSessionRevocations(b.getSessionTTL())
+		})
+	}
+
+	// credentials are kept in memory of each instance
+	s.register("vipRefresh", b.config.VipRefresh.Enabled, false, 10*time.Minute, time.Minute, b.runVipRefresh)
+
+	// local limiter and key cache of this instance
+	s.register("cleanupLocalCache", true, false, defaultJobInterval, 0, func() (int64, error) {
+		aff := b.uidLimiter.cleanup()
+		aff += b.searchLimiter.cleanup()
+		aff += b.ipLimiter.cleanup()
+		aff += b.authCache.cleanup(authCacheDuration)
+		aff += b.nonces.cleanup()
+		aff += b.areas.cleanup()
+		return aff, nil
+	})
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/time/rate"
+)
+
+const defaultVisitorMaxEntries = 100000
+
+// visitorStore per key limiters with bounded size, idle limiters expire after authCacheDuration
+type visitorStore struct {
+	visitors *shardedCache[*rate.Limiter]
+	limit    rate.Limit
+	burst    int
+}
+
+func newVisitorStore(name string, limit int, burst int, maxEntries int, m *metrics) *visitorStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultVisitorMaxEntries
+	}
+	rt := rate.Inf
+	if limit > 0 {
+		rt = rate.Every(time.Second / time.Duration(limit))
+	}
+	visitors := newShardedCache[*rate.Limiter](name+"Limiter", maxEntries, authCacheDuration, true, m)
+	// limited visitors must not get a fresh bucket by flooding new keys
+	visitors.evictable = func(limiter *rate.Limiter) bool {
+		return limiter.TokensAt(time.Now()) >= float64(limiter.Burst())
+	}
+	return &visitorStore{
+		visitors: visitors,
+		limit:    rt,
+		burst:    burst,
+	}
+}
+
+// get get or create limiter, evict the least recently used idle visitor when full
+func (s *visitorStore) get(key string) *rate.Limiter {
+	return s.visitors.getOrCreate(key, func() *rate.Limiter {
+		return rate.NewLimiter(s.limit, s.burst)
+	})
+}
+
+// cleanup remove visitors idle longer than authCacheDuration
+func (s *visitorStore) cleanup() int64 {
+	return s.visitors.cleanup()
+}
+
+// limitResult limiter state after a request
+type limitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// allowLimiter take one token and report the limiter state
+func allowLimiter(limiter *rate.Limiter) *limitResult {
+	now := time.Now()
+	result := &limitResult{
+		allowed: true,
+		limit:   limiter.Burst(),
+	}
+
+	if limiter.Limit() == rate.Inf {
+		result.remaining = result.limit
+		return result
+	}
+
+	r := limiter.ReserveN(now, 1)
+	if !r.OK() {
+		result.allowed = false
+		result.retryAfter = time.Second
+	} else if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		result.allowed = false
+		result.retryAfter = delay
+	}
+
+	fillLimitResult(result, limiter.Limit(), limiter.TokensAt(now))
+	return result
+}
+
+// peekLimiter report the limiter state without taking token
+func peekLimiter(limiter *rate.Limiter) *limitResult {
+	result := &limitResult{
+		allowed:   true,
+		limit:     limiter.Burst(),
+		remaining: limiter.Burst(),
+	}
+	if limiter.Limit() == rate.Inf {
+		return result
+	}
+
+	tokens := limiter.TokensAt(time.Now())
+	if tokens < 1 {
+		result.allowed = false
+		if limiter.Limit() > 0 {
+			result.retryAfter = time.Duration((1 - tokens) / float64(limiter.Limit()) * float64(time.Second))
+		}
+	}
+	fillLimitResult(result, limiter.Limit(), tokens)
+	return result
+}
+
+// fillLimitResult remaining tokens and time until bucket is full
+func fillLimitResult(result *limitResult, limit rate.Limit, tokens float64) {
+	result.remaining = 0
+	if tokens > 0 {
+		result.remaining = int(math.Floor(tokens))
+	}
+	if limit > 0 {
+		if missing := float64(result.limit) - tokens; missing > 0 {
+			result.reset = time.Duration(missing / float64(limit) * float64(time.Second))
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+func setRateLimitHeaders(ctx *fasthttp.RequestCtx, result *limitResult) {
+	ctx.Response.Header.Set("X-RateLimit-Limit", strconv.Itoa(result.limit))
+	ctx.Response.Header.Set("X-RateLimit-Remaining", strconv.Itoa(result.remaining))
+	ctx.Response.Header.Set("X-RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.reset), 10))
+	if !result.allowed {
+		ctx.Response.Header.Set("Retry-After", strconv.FormatInt(ceilSeconds(result.retryAfter), 10))
+	}
+}
+
+// checkLimitResult set rate limit headers, write error when rejected
+func checkLimitResult(ctx *fasthttp.RequestCtx, result *limitResult) bool {
+	setRateLimitHeaders(ctx, result)
+	if !result.allowed {
+		writeErrorJSON(ctx, ERROR_CODE_TOO_MANY_REQUESTS, fmt.Sprintf(MSG_ERROR_TOO_MANY_REQUESTS_RETRY, ceilSeconds(result.retryAfter)))
+		return false
+	}
+	return true
+}
+
+// takeLimiter fail open when limiter backend is unavailable
+func (b *BiliroamingGo) takeLimiter(ctx *fasthttp.RequestCtx, limiter limiterBackend, key string) (*limitResult, bool) {
+	result, err := limiter.allow(key)
+	if err != nil {
+		b.sugar.Error(err)
+	}
+	if !result.allowed && b.reportOnly(policyQuota, key) {
+		return result, true
+	}
+	return result, checkLimitResult(ctx, result)
+}
+
+// peekLimiter limiter state for display, never consume or reject
+func (b *BiliroamingGo) peekLimiter(limiter limiterBackend, key string) *limitResult {
+	result, err := limiter.peek(key)
+	if err != nil {
+		b.sugar.Error(err)
+	}
+	return result
+}
+
+func (b *BiliroamingGo) checkLimiter(ctx *fasthttp.RequestCtx, limiter limiterBackend, key string) bool {
+	_, ok := b.takeLimiter(ctx, limiter, key)
+	return ok
+}
+
+func (b *BiliroamingGo) doCheckUidLimiter(ctx *fasthttp.RequestCtx, uid int64) bool {
+	return b.checkLimiter(ctx, b.uidLimiter, strconv.FormatInt(uid, 10))
+}
+
+// getVisitorKey per-UID when access key is known, otherwise per-IP
+func (b *BiliroamingGo) getVisitorKey(ctx *fasthttp.RequestCtx, accessKey string) string {
+	if accessKey != "" {
+		if key, ok := b.getKey(accessKey); ok && key.uid > 0 {
+			return fmt.Sprintf("uid:%d", key.uid)
+		}
+	}
+	return "ip:" + getClientIP(ctx).String()
+}
+
+func (b *BiliroamingGo) doCheckSearchLimiter(ctx *fasthttp.RequestCtx) bool {
+	accessKey := string(ctx.URI().QueryArgs().Peek("access_key"))
+	return b.checkLimiter(ctx, b.searchLimiter, b.getVisitorKey(ctx, accessKey))
+}
+
+func (b *BiliroamingGo) doCheckIpLimiter(ctx *fasthttp.RequestCtx) bool {
+	return b.checkLimiter(ctx, b.ipLimiter, getClientIP(ctx).String())
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+)
+
+func TestVisitorStoreAllowPeek(t *testing.T) {
+	s := newVisitorStore("test", 1, 2, 100, nil)
+
+	if result, _ := s.peek("a"); !result.allowed || result.remaining != 2 || result.limit != 2 {
+		t.Errorf("peek fresh = %+v", result)
+	}
+	for i := 0; i < 2; i++ {
+		if result, _ := s.allow("a"); !result.allowed {
+			t.Fatalf("request %d rejected", i)
+		}
+	}
+	result, _ := s.allow("a")
+	if result.allowed {
+		t.Fatal("request over burst allowed")
+	}
+	if result.remaining != 0 || result.retryAfter <= 0 || result.retryAfter > time.Second {
+		t.Errorf("rejected result = %+v", result)
+	}
+
+	// peek never takes token
+	for i := 0; i < 3; i++ {
+		if result, _ := s.peek("a"); result.allowed || result.remaining != 0 {
+			t.Errorf("peek exhausted = %+v", result)
+		}
+	}
+	if result, _ := s.allow("b"); !result.allowed {
+		t.Error("other key limited")
+	}
+}
+
+func TestVisitorStoreUnlimited(t *testing.T) {
+	s := newVisitorStore("test", 0, 3, 100, nil)
+	for i := 0; i < 10; i++ {
+		if result, _ := s.allow("a"); !result.allowed || result.remaining != 3 {
+			t.Fatalf("unlimited result = %+v", result)
+		}
+	}
+}
+
+func TestVisitorStoreKeepsLimitedVisitors(t *testing.T) {
+	s := newVisitorStore("test", 1, 1, cacheShardCount, nil)
+	keys := sameShardKeys(s.visitors, cacheEvictScan+2)
+
+	if result, _ := s.allow(keys[0]); !result.allowed {
+		t.Fatal("first request rejected")
+	}
+	// spray new keys into the full shard
+	for _, key := range keys[1:] {
+		s.allow(key)
+	}
+	if result, _ := s.allow(keys[0]); result.allowed {
+		t.Error("limited visitor evicted by new keys")
+	}
+}
+
+func TestVisitorStoreEvictsIdleVisitors(t *testing.T) {
+	s := newVisitorStore("test", 1, 1, cacheShardCount, nil)
+	keys := sameShardKeys(s.visitors, 3)
+
+	s.get(keys[0])
+	s.get(keys[1])
+	if _, ok := s.visitors.get(keys[0]); ok {
+		t.Error("idle visitor not evicted")
+	}
+	if n := len(s.visitors.getShard(keys[0]).items); n != 1 {
+		t.Errorf("shard has %d entries, want 1", n)
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	if !checkLimitResult(ctx, &limitResult{allowed: true, limit: 5, remaining: 3, reset: 1500 * time.Millisecond}) {
+		t.Fatal("allowed result rejected")
+	}
+	for name, want := range map[string]string{
+		"X-RateLimit-Limit":     "5",
+		"X-RateLimit-Remaining": "3",
+		"X-RateLimit-Reset":     "2",
+	} {
+		if got := string(ctx.Response.Header.Peek(name)); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if v := ctx.Response.Header.Peek("Retry-After"); v != nil {
+		t.Errorf("Retry-After on allowed request = %q", v)
+	}
+
+	ctx = &fasthttp.RequestCtx{}
+	if checkLimitResult(ctx, &limitResult{limit: 5, reset: 5 * time.Second, retryAfter: 200 * time.Millisecond}) {
+		t.Fatal("rejected result allowed")
+	}
+	if got := string(ctx.Response.Header.Peek("Retry-After")); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+	if got := string(ctx.Response.Header.Peek("X-RateLimit-Remaining")); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q, want 0", got)
+	}
+	resp := &entity.SimpleResponse{}
+	if err := easyjson.Unmarshal(ctx.Response.Body(), resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != ERROR_CODE_TOO_MANY_REQUESTS {
+		t.Errorf("code = %d, want %d", resp.Code, ERROR_CODE_TOO_MANY_REQUESTS)
+	}
+}
+
+func TestTakeLimiterReportOnly(t *testing.T) {
+	c := &Config{}
+	b := newTestBiliroaming(t, c)
+	s := newVisitorStore("test", 1, 1, 100, nil)
+	s.allow("a")
+
+	ctx := &fasthttp.RequestCtx{}
+	if _, ok := b.takeLimiter(ctx, s, "a"); ok {
+		t.Error("exhausted limiter allowed")
+	}
+
+	c.Policies = map[string]PolicyMode{policyQuota: PolicyModeReport}
+	ctx = &fasthttp.RequestCtx{}
+	if _, ok := b.takeLimiter(ctx, s, "a"); !ok {
+		t.Error("report only quota rejected")
+	}
+}
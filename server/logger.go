@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedValue = "***"
+
+// redactPatterns credentials and personal data, for query string, cookie, json and format strings
+var redactPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{
+		re:   regexp.MustCompile(`(?i)(^|[?&;,\s])(access_key|access_token|refresh_token|sign|appkey|actionKey|csrf|bili_jct|SESSDATA|buvid3|DedeUserID__ckMd5)=[^&;,\s"']*`),
+		repl: "${1}${2}=" + redactedValue,
+	},
+	{
+		re:   regexp.MustCompile(`(?i)("(?:access_key|access_token|refresh_token|sign|appkey|cookie|SESSDATA|buvid3|name|uname)"\s*:\s*)"[^"]*"`),
+		repl: `${1}"` + redactedValue + `"`,
+	},
+	{
+		re:   regexp.MustCompile(`(?i)(\b(?:name|uname|cookie):\s*)[^,\s]+`),
+		repl: "${1}" + redactedValue,
+	},
+	// bare access key
+	{
+		re:   regexp.MustCompile(`\b[0-9a-f]{32}\b`),
+		repl: redactedValue,
+	},
+}
+
+func redact(s string) string {
+	for _, p := range redactPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = redact(f.String)
+		case zapcore.ByteStringType:
+			if v, ok := f.Interface.([]byte); ok {
+				f = zap.String(f.Key, redact(string(v)))
+			}
+		case zapcore.ErrorType:
+			if v, ok := f.Interface.(error); ok {
+				f = zap.String(f.Key, redact(v.Error()))
+			}
+		case zapcore.StringerType:
+			if v, ok := f.Interface.(fmt.Stringer); ok {
+				f = zap.String(f.Key, redact(v.String()))
+			}
+		}
+		redacted[i] = f
+	}
+	return redacted
+}
+
+// redactCore mask secrets in message and fields before encoding
+type redactCore struct {
+	zapcore.Core
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{c.Core.With(redactFields(fields))}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = redact(ent.Message)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactOption wrap core with redactCore once
+func redactOption() zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if _, ok := core.(*redactCore); ok {
+			return core
+		}
+		return &redactCore{core}
+	})
+}
+
+// NewLogger logger with credentials redacted
+func NewLogger(isDebug bool) (*zap.Logger, error) {
+	if isDebug {
+		return zap.NewDevelopment(redactOption())
+	}
+	return zap.NewProduction(redactOption())
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/valyala/fasthttp"
+)
+
+var meAreas = []string{"cn", "hk", "tw", "th"}
+
+// getUserStatus status by the same credentials as doAuth (session token, access key or SESSDATA cookie),
+// never rejects blocked users, write error and return false if not login
+func (b *BiliroamingGo) getUserStatus(ctx *fasthttp.RequestCtx, accessKey string, clientType ClientType) (*userStatus, bool) {
+	if token := b.getSessionToken(ctx); token != "" {
+		claims, err := b.verifySession(token)
+		if err != nil {
+			b.sugar.Debug(err)
+			writeErrorJSON(ctx, ERROR_CODE_AUTH_SESSION, MSG_ERROR_AUTH_SESSION)
+			return nil, false
+		}
+		status, err := b.getSessionBWlist(ctx, claims)
+		if err != nil {
+			b.processError(ctx, err)
+			return nil, false
+		}
+		return status, true
+	}
+
+	var status *userStatus
+	var err error
+	if len(accessKey) == 0 {
+		sessdata := getSessdata(ctx)
+		if sessdata == "" {
+			writeErrorJSON(ctx, ERROR_CODE_AUTH_NOT_LOGIN, MSG_ERROR_AUTH_NOT_LOGIN)
+			return nil, false
+		}
+		status, err = b.getCachedUserStatus(cookieCacheKey(sessdata), func() (*userStatus, error) {
+			return b.isCookieAuth(ctx, sessdata, false)
+		})
+	} else if len(accessKey) != 32 {
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_ACCESS_KEY, MSG_ERROR_AUTH_ACCESS_KEY)
+		return nil, false
+	} else {
+		status, err = b.getCachedUserStatus(accessKey, func() (*userStatus, error) {
+			return b.isAuth(ctx, accessKey, clientType, false)
+		})
+	}
+	if err != nil {
+		b.processError(ctx, err)
+		return nil, false
+	}
+	if !status.isLogin {
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_NOT_LOGIN, MSG_ERROR_AUTH_NOT_LOGIN)
+		return nil, false
+	}
+	return status, true
+}
+
+// getCachedUserStatus cached status by key or fetch by isAuth
+func (b *BiliroamingGo) getCachedUserStatus(cacheKey string, isAuth func() (*userStatus, error)) (*userStatus, error) {
+	if key, ok := b.getKey(cacheKey); ok {
+		return &userStatus{
+			isLogin:     key.isLogin,
+			isVip:       key.isVip,
+			isBlacklist: key.isBlacklist,
+			isWhitelist: key.isWhitelist,
+			uid:         key.uid,
+			banUntil:    key.banUntil,
+		}, nil
+	}
+	status, err := isAuth()
+	if err != nil {
+		return nil, err
+	}
+	b.setKey(cacheKey, status)
+	return status, nil
+}
+
+// isBlocked blacklist or whitelist policy rejects user
+func (b *BiliroamingGo) isBlocked(status *userStatus) bool {
+	switch b.config.BlockType {
+	case BlockTypeEnabled:
+		return status.isBlacklist && b.getPolicyMode(policyBlacklist) != PolicyModeReport
+	case BlockTypeWhitelist:
+		return !status.isWhitelist && b.getPolicyMode(policyWhitelist) != PolicyModeReport
+	}
+	return false
+}
+
+// getUserAreas areas usable by user
+func (b *BiliroamingGo) getUserAreas(status *userStatus) []string {
+	areas := make([]string, 0, len(meAreas))
+	blocked := b.isBlocked(status)
+	vipOnly := b.config.VipOnly && !status.isVip && b.getPolicyMode(policyVipOnly) != PolicyModeReport
+	for _, area := range meAreas {
+		if b.getAuthByArea(area) && (blocked || vipOnly) {
+			continue
+		}
+		areas = append(areas, area)
+	}
+	return areas
+}
+
+// handleMe 用户查询自身状态
+func (b *BiliroamingGo) handleMe(ctx *fasthttp.RequestCtx) {
+	queryArgs := ctx.URI().QueryArgs()
+	accessKey := string(queryArgs.Peek("access_key"))
+	clientType := b.getClientPlatform(ctx, string(queryArgs.Peek("appkey")))
+
+	status, ok := b.getUserStatus(ctx, accessKey, clientType)
+	if !ok {
+		return
+	}
+
+	// report quota of uid limiter without taking token, ip limiter still applies
+	result := b.peekLimiter(b.uidLimiter, strconv.FormatInt(status.uid, 10))
+
+	mid, accountType := splitUID(status.uid)
+	data := entity.MeData{
+		UID:         mid,
+		AccountType: accountType,
+		IsVip:       status.isVip,
+		IsBlacklist: status.isBlacklist,
+		IsWhitelist: status.isWhitelist,
+		Group:       getUserGroup(status),
+		RateLimit: entity.MeRateLimit{
+			Limit:     result.limit,
+			Remaining: result.remaining,
+			Reset:     ceilSeconds(result.reset),
+		},
+		Areas: b.getUserAreas(status),
+	}
+	if status.isBlacklist && status.banUntil.After(time.Now()) {
+		data.BanUntil = status.banUntil.Unix()
+	}
+	writeJSON(ctx, &entity.MeResponse{
+		Code:    0,
+		Message: "0",
+		Data:    data,
+	})
+}
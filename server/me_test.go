@@ -0,0 +1,123 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestMeSessionTokenKeepsQuota(t *testing.T) {
+	c := &Config{}
+	c.Limiter.Limit = 1
+	c.Limiter.Burst = 1
+	b := newTestSessionServer(t, c)
+	token, _, err := b.issueSession(&userStatus{uid: 1, isLogin: true, isVip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+token)
+		b.handleMe(ctx)
+
+		resp := &entity.MeResponse{}
+		if err := easyjson.Unmarshal(ctx.Response.Body(), resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Code != 0 {
+			t.Fatalf("request %d: code = %d, message = %s", i, resp.Code, resp.Message)
+		}
+		if resp.Data.UID != 1 || !resp.Data.IsVip {
+			t.Errorf("request %d: data = %+v", i, resp.Data)
+		}
+		if resp.Data.RateLimit.Remaining != 1 {
+			t.Errorf("request %d: remaining = %d, want 1", i, resp.Data.RateLimit.Remaining)
+		}
+	}
+
+	// quota is still available for real requests
+	ctx := &fasthttp.RequestCtx{}
+	if !b.doCheckUidLimiter(ctx, 1) {
+		t.Error("uid limiter consumed by /api/me")
+	}
+}
+
+func TestMeNotLogin(t *testing.T) {
+	b := newTestBiliroaming(t, nil)
+	for _, accessKey := range []string{"", "short"} {
+		ctx := &fasthttp.RequestCtx{}
+		if accessKey != "" {
+			ctx.Request.SetRequestURI("/api/me?access_key=" + accessKey)
+		}
+		b.handleMe(ctx)
+
+		resp := &entity.MeResponse{}
+		if err := easyjson.Unmarshal(ctx.Response.Body(), resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Code == 0 {
+			t.Errorf("access_key %q accepted", accessKey)
+		}
+	}
+}
+
+func TestPeekLimiter(t *testing.T) {
+	s := newVisitorStore("test", 1, 2, 10, newMetrics())
+	if result, _ := s.peek("a"); !result.allowed || result.remaining != 2 {
+		t.Fatalf("peek unknown key = %+v", result)
+	}
+	s.allow("a")
+	s.allow("a")
+	result, _ := s.peek("a")
+	if result.allowed || result.remaining != 0 {
+		t.Fatalf("peek exhausted key = %+v", result)
+	}
+	if result.retryAfter <= 0 || result.retryAfter > time.Second {
+		t.Errorf("retryAfter = %s", result.retryAfter)
+	}
+}
+
+func TestMeBstarAccount(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	var path string
+	srv := &fasthttp.Server{Handler: func(ctx *fasthttp.RequestCtx) {
+		path = string(ctx.Path())
+		ctx.SetContentType("application/json")
+		ctx.WriteString(`{"code":0,"data":{"status":1,"ban_until":0}}`)
+	}}
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		srv.Shutdown()
+	})
+
+	b := newTestSessionServer(t, &Config{BlockType: BlockTypeEnabled, BlacklistApiUrl: "http://blacklist.test/users/%d"})
+	b.defaultClient = &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) {
+			return ln.Dial()
+		},
+	}
+	token, _, err := b.issueSession(&userStatus{uid: bstarUID(42), isLogin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+token)
+	b.handleMe(ctx)
+	resp := &entity.MeResponse{}
+	if err := easyjson.Unmarshal(ctx.Response.Body(), resp); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/users/42" {
+		t.Errorf("blacklist api path = %s, want raw mid", path)
+	}
+	if resp.Data.UID != 42 || resp.Data.AccountType != accountTypeBstar || !resp.Data.IsBlacklist {
+		t.Errorf("data = %+v", resp.Data)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// metrics counters and gauges in prometheus text format
+type metrics struct {
+	mu       sync.RWMutex
+	counters map[string]*atomic.Int64
+	gauges   map[string]float64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		counters: make(map[string]*atomic.Int64),
+		gauges:   make(map[string]float64),
+	}
+}
+
+// metricKey labels are key value pairs
+func metricKey(name string, labels ...string) string {
+	if len(labels) < 2 {
+		return name
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", labels[i], strconv.Quote(labels[i+1])))
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// counter get or register counter, hot paths keep the pointer to skip key lookup
+func (m *metrics) counter(name string, labels ...string) *atomic.Int64 {
+	key := metricKey(name, labels...)
+
+	m.mu.RLock()
+	counter, ok := m.counters[key]
+	m.mu.RUnlock()
+	if !ok {
+		m.mu.Lock()
+		if counter, ok = m.counters[key]; !ok {
+			counter = &atomic.Int64{}
+			m.counters[key] = counter
+		}
+		m.mu.Unlock()
+	}
+	return counter
+}
+
+func (m *metrics) add(name string, delta int64, labels ...string) {
+	m.counter(name, labels...).Add(delta)
+}
+
+func (m *metrics) inc(name string, labels ...string) {
+	m.add(name, 1, labels...)
+}
+
+func (m *metrics) set(name string, value float64, labels ...string) {
+	key := metricKey(name, labels...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges[key] = value
+}
+
+func (m *metrics) writeTo(w io.Writer) {
+	m.mu.RLock()
+	lines := make([]string, 0, len(m.counters)+len(m.gauges))
+	for key, counter := range m.counters {
+		lines = append(lines, fmt.Sprintf("%s %d", key, counter.Load()))
+	}
+	for key, value := range m.gauges {
+		lines = append(lines, fmt.Sprintf("%s %s", key, strconv.FormatFloat(value, 'f', -1, 64)))
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,423 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/entity/android"
+	"github.com/JasonKhew96/biliroaming-go-server/entity/bstar"
+	"github.com/JasonKhew96/biliroaming-go-server/entity/web"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/idna"
+)
+
+func (b *BiliroamingGo) checkEpisodeAreaCache(episodeId int64, area database.Area) bool {
+	if cache, err := b.getEpisodeAreaCache(episodeId); err == nil {
+		// shit happened
+		if !cache.CN.Bool && !cache.HK.Bool && !cache.TW.Bool && !cache.TH.Bool {
+			return true
+		}
+		switch area {
+		case database.AreaCN:
+			if cache.CN.Valid && !cache.CN.Bool {
+				return false
+			}
+		case database.AreaHK:
+			if cache.HK.Valid && !cache.HK.Bool {
+				return false
+			}
+		case database.AreaTW:
+			if cache.TW.Valid && !cache.TW.Bool {
+				return false
+			}
+		case database.AreaTH:
+			if cache.TH.Valid && !cache.TH.Bool {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BiliroamingGo) updateEpisodeCache(data []byte, episodeId int64, area database.Area) error {
+	if available, err := isAvailableResponse(data); err != nil {
+		return err
+	} else if err := b.setEpisodeAreaCache(episodeId, area, available); err != nil {
+		return err
+	}
+	return nil
+}
+
+// recoverVipMismatch re-auth when upstream vip status disagrees with cached status,
+// ok is false if error response already written
+func (b *BiliroamingGo) recoverVipMismatch(ctx *fasthttp.RequestCtx, accessKey string, clientType ClientType, area string, status *userStatus) (*userStatus, bool) {
+	b.sugar.Debugf("VIP status mismatch for uid %d, cached %t", status.uid, status.isVip)
+	if accessKey != "" {
+		b.deleteKey(accessKey)
+	} else if sessdata := getSessdata(ctx); sessdata != "" {
+		b.deleteKey(cookieCacheKey(sessdata))
+	}
+	ok, newStatus := b.doAuth(ctx, accessKey, clientType, area, true)
+	if !ok {
+		b.metrics.inc("biliroaming_vip_mismatch_total", "client", string(clientType), "result", "failure")
+		return nil, false
+	}
+	b.metrics.inc("biliroaming_vip_mismatch_total", "client", string(clientType), "result", "recovered")
+	return newStatus, true
+}
+
+// playUrlPlatform 各平台 playurl 差异
+type playUrlPlatform struct {
+	deviceType database.DeviceType
+	// clientType fixed client type, empty use client type of request appkey
+	clientType    ClientType
+	path          string
+	defaultDomain string
+	// forwardCookie forward SESSDATA cookie when access_key is empty
+	forwardCookie bool
+	// preferCodeType prefer_code_type is part of cache key
+	preferCodeType bool
+	extraParams    func(v url.Values, args *biliArgs)
+	patchQn        func(data []byte, qn int) ([]byte, error)
+	// vipStatus ok false when vip status is unknown from response
+	vipStatus func(data []byte) (ok bool, isVip bool, err error)
+}
+
+var webPlayUrlPlatform = &playUrlPlatform{
+	deviceType:    database.DeviceTypeWeb,
+	path:          "/pgc/player/web/playurl",
+	defaultDomain: "api.bilibili.com",
+	forwardCookie: true,
+	extraParams: func(v url.Values, args *biliArgs) {
+		v.Set("area", args.area)
+	},
+	patchQn: func(data []byte, qn int) ([]byte, error) {
+		var playUrl web.PlayUrlResult
+		if err := easyjson.Unmarshal(data, &playUrl); err != nil {
+			return nil, err
+		}
+		if playUrl.Code != 0 {
+			return data, nil
+		}
+		playUrl.Result.Quality = qn
+		return easyjson.Marshal(playUrl)
+	},
+	vipStatus: func(data []byte) (bool, bool, error) {
+		var playUrl web.PlayUrlResult
+		if err := easyjson.Unmarshal(data, &playUrl); err != nil {
+			return false, false, err
+		}
+		return playUrl.Code == 0, playUrl.Result.VipStatus != 0, nil
+	},
+}
+
+var androidPlayUrlPlatform = &playUrlPlatform{
+	deviceType:    database.DeviceTypeAndroid,
+	path:          "/pgc/player/api/playurl",
+	defaultDomain: "api.bilibili.com",
+	extraParams: func(v url.Values, args *biliArgs) {
+		v.Set("area", args.area)
+		v.Set("platform", "android")
+	},
+	patchQn: func(data []byte, qn int) ([]byte, error) {
+		var playUrl android.PlayUrlResult
+		if err := easyjson.Unmarshal(data, &playUrl); err != nil {
+			return nil, err
+		}
+		if playUrl.Code != 0 {
+			return data, nil
+		}
+		playUrl.Quality = qn
+		return easyjson.Marshal(playUrl)
+	},
+	vipStatus: func(data []byte) (bool, bool, error) {
+		var playUrl android.PlayUrlResult
+		if err := easyjson.Unmarshal(data, &playUrl); err != nil {
+			return false, false, err
+		}
+		return playUrl.Code == 0, playUrl.VipStatus != 0, nil
+	},
+}
+
+var bstarPlayUrlPlatform = &playUrlPlatform{
+	deviceType:     database.DeviceTypeAndroid,
+	clientType:     ClientTypeBstarA,
+	path:           "/intl/gateway/v2/ogv/playurl",
+	defaultDomain:  "api.biliintl.com",
+	preferCodeType: true,
+	extraParams: func(v url.Values, args *biliArgs) {
+		v.Set("platform", "android")
+		v.Set("s_locale", "zh_SG")
+		if args.preferCodeType {
+			v.Set("prefer_code_type", "1")
+		}
+	},
+	patchQn: func(data []byte, qn int) ([]byte, error) {
+		var playUrl bstar.PlayUrlResult
+		if err := easyjson.Unmarshal(data, &playUrl); err != nil {
+			return nil, err
+		}
+		if playUrl.Code != 0 {
+			return data, nil
+		}
+		playUrl.Data.VideoInfo.Quality = qn
+		return easyjson.Marshal(playUrl)
+	},
+	// no vip flag in bstar response, vip streams with url means vip,
+	// unknown when episode has no vip stream
+	vipStatus: func(data []byte) (bool, bool, error) {
+		var playUrl bstar.PlayUrlStreams
+		if err := easyjson.Unmarshal(data, &playUrl); err != nil {
+			return false, false, err
+		}
+		if playUrl.Code != 0 {
+			return false, false, nil
+		}
+		hasVipStream := false
+		for _, stream := range playUrl.Data.VideoInfo.StreamList {
+			if !stream.StreamInfo.NeedVip {
+				continue
+			}
+			hasVipStream = true
+			if stream.DashVideo != nil && stream.DashVideo.BaseUrl != "" {
+				return true, true, nil
+			}
+		}
+		return hasVipStream, false, nil
+	},
+}
+
+// playUrlQuery cache key of playurl request
+type playUrlQuery struct {
+	epId           int64
+	qn             int
+	formatType     database.FormatType
+	area           database.Area
+	preferCodeType bool
+}
+
+// checkVipOnly unauthenticated status means auth is disabled for area, always allowed
+func (b *BiliroamingGo) checkVipOnly(ctx *fasthttp.RequestCtx, status *userStatus) bool {
+	if !b.config.VipOnly || status.unauthenticated || status.isVip {
+		return true
+	}
+	if b.reportOnly(policyVipOnly, fmt.Sprintf("uid %d", status.uid)) {
+		return true
+	}
+	writeErrorJSON(ctx, ERROR_CODE_VIP_ONLY, MSG_ERROR_VIP_ONLY)
+	return false
+}
+
+func (b *BiliroamingGo) handlePlayURL(p *playUrlPlatform) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		b.servePlayURL(ctx, p)
+	}
+}
+
+func (b *BiliroamingGo) servePlayURL(ctx *fasthttp.RequestCtx, p *playUrlPlatform) {
+	args := b.getArgs(ctx)
+
+	if args.area == "" {
+		writeErrorJSON(ctx, ERROR_CODE_GEO_RESTRICED, MSG_ERROR_GEO_RESTRICTED)
+		return
+	}
+
+	// 验证 epId
+	if args.epId == 0 {
+		writeErrorJSON(ctx, ERROR_CODE_PARAMETERS, MSG_ERROR_PARAMETERS)
+		return
+	}
+
+	if ok := b.checkEpisodeAreaCache(args.epId, getAreaCode(args.area)); !ok {
+		writeErrorJSON(ctx, ERROR_CODE_GEO_RESTRICED, MSG_ERROR_GEO_RESTRICTED)
+		return
+	}
+
+	query := playUrlQuery{
+		epId:           args.epId,
+		qn:             args.qn,
+		formatType:     getFormatType(args.fnval),
+		area:           getAreaCode(args.area),
+		preferCodeType: p.preferCodeType && args.preferCodeType,
+	}
+	if query.formatType == database.FormatTypeDash {
+		query.qn = 127
+	}
+
+	clientType := p.clientType
+	if clientType == "" {
+		clientType = b.getClientPlatform(ctx, args.appkey)
+	}
+
+	status := getStatus(ctx)
+	if !status.unauthenticated {
+		if done := b.replayPlayUrlCache(ctx, p, args, query, status); done {
+			return
+		}
+	}
+
+	data, forwarded, ok := b.fetchPlayUrl(ctx, p, args, query, clientType)
+	if !ok {
+		return
+	}
+
+	setDefaultHeaders(ctx)
+
+	if isNotLogin, err := isResponseNotLogin(data); err != nil {
+		b.sugar.Error(err)
+	} else if isNotLogin {
+		ctx.Write(data)
+		return
+	}
+
+	if isLimited, err := isResponseLimited(data); err != nil {
+		b.sugar.Error(err)
+	} else if isLimited {
+		b.updateHealth(b.getPlayUrlHealth(args.area), ERROR_CODE_TOO_MANY_REQUESTS, MSG_ERROR_TOO_MANY_REQUESTS)
+	} else {
+		b.updateHealth(b.getPlayUrlHealth(args.area), 0, "0")
+	}
+
+	data, err := p.patchQn(data, args.qn)
+	if err != nil {
+		b.processError(ctx, err)
+		return
+	}
+
+	if err := b.updateEpisodeCache(data, args.epId, query.area); err != nil {
+		b.sugar.Error(err)
+	}
+
+	if !status.unauthenticated {
+		// cache by upstream vip status, response is valid for the actual account
+		cacheVip := status.upstreamVip(forwarded)
+		if ok, isStatusVip, err := p.vipStatus(data); err != nil {
+			b.sugar.Error(err)
+		} else if ok {
+			if isStatusVip != cacheVip {
+				if status, ok = b.recoverVipMismatch(ctx, args.accessKey, clientType, args.area, status); !ok {
+					return
+				}
+			}
+			cacheVip = isStatusVip
+		}
+		if err := b.db.InsertOrUpdatePlayURLCache(p.deviceType, query.formatType, int16(query.qn), query.area, cacheVip, query.preferCodeType, query.epId, data); err != nil {
+			b.sugar.Error(err)
+		}
+	}
+
+	if !b.checkVipOnly(ctx, status) {
+		return
+	}
+
+	ctx.Write(data)
+}
+
+// replayPlayUrlCache return true if response already written
+func (b *BiliroamingGo) replayPlayUrlCache(ctx *fasthttp.RequestCtx, p *playUrlPlatform, args *biliArgs, query playUrlQuery, status *userStatus) bool {
+	playurlCache, err := b.db.GetPlayURLCache(p.deviceType, query.formatType, int16(query.qn), query.area, status.isVip, query.preferCodeType, query.epId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false
+		}
+		b.processError(ctx, err)
+		b.updateHealth(b.getPlayUrlHealth(args.area), ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+		return true
+	}
+	if len(playurlCache.Data) == 0 || !playurlCache.UpdatedAt.After(time.Now().Add(-b.config.Cache.PlayUrl)) {
+		return false
+	}
+
+	if !b.checkVipOnly(ctx, status) {
+		return true
+	}
+
+	b.sugar.Debug("Replay from cache: ", playurlCache.Data.String())
+	setDefaultHeaders(ctx)
+	data, err := p.patchQn(playurlCache.Data, args.qn)
+	if err != nil {
+		b.processError(ctx, err)
+		return true
+	}
+	ctx.Write(data)
+	return true
+}
+
+// fetchPlayUrl request upstream, forwarded is true when user credential is sent,
+// ok is false if error response already written
+func (b *BiliroamingGo) fetchPlayUrl(ctx *fasthttp.RequestCtx, p *playUrlPlatform, args *biliArgs, query playUrlQuery, clientType ClientType) (data []byte, forwarded bool, ok bool) {
+	v := url.Values{}
+	v.Set("access_key", args.accessKey)
+	v.Set("ep_id", strconv.FormatInt(args.epId, 10))
+	v.Set("fnver", "0")
+
+	switch query.formatType {
+	case database.FormatTypeDash:
+		v.Set("fnval", "4048")
+	case database.FormatTypeMp4:
+		v.Set("fnval", "1")
+	case database.FormatTypeFlv:
+		fallthrough
+	default:
+		v.Set("fnval", "0")
+	}
+
+	v.Set("fourk", "1")
+	v.Set("qn", strconv.Itoa(query.qn))
+	if p.extraParams != nil {
+		p.extraParams(v, args)
+	}
+
+	params, err := b.SignParams(v, clientType)
+	if err != nil {
+		b.processError(ctx, err)
+		return nil, false, false
+	}
+
+	reverseProxy := b.getReverseProxyByArea(args.area)
+	if reverseProxy == "" {
+		reverseProxy = p.defaultDomain
+	}
+	domain, err := idna.New().ToASCII(reverseProxy)
+	if err != nil {
+		b.processError(ctx, err)
+		return nil, false, false
+	}
+
+	url := fmt.Sprintf("https://%s%s?%s", domain, p.path, params)
+	b.sugar.Debug("New url: ", url)
+
+	reqParams := &HttpRequestParams{
+		Method:    []byte(fasthttp.MethodGet),
+		Url:       []byte(url),
+		UserAgent: ctx.UserAgent(),
+	}
+	forwarded = args.accessKey != ""
+	// web cookie credential
+	if sessdata := getSessdata(ctx); p.forwardCookie && !forwarded && sessdata != "" {
+		reqParams.Cookie = append(reqParams.Cookie, HttpCookiesParams{
+			Key:   []byte(cookieSessdata),
+			Value: []byte(sessdata),
+		})
+		forwarded = true
+	}
+	data, err = b.doRequestJson(b.getClientByArea(args.area), reqParams)
+	if err != nil {
+		if errors.Is(err, ErrorHttpStatusLimited) {
+			data = []byte(`{"code":-412,"message":"请求被拦截"}`)
+		} else {
+			b.processError(ctx, err)
+			b.updateHealth(b.getPlayUrlHealth(args.area), ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+			return nil, false, false
+		}
+	}
+	return data, forwarded, true
+}
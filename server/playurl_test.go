@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestAuthMiddlewareUnauthenticatedStatus(t *testing.T) {
+	b := newTestBiliroaming(t, nil)
+	var status *userStatus
+	handler := b.authMiddleware(false)(func(ctx *fasthttp.RequestCtx) {
+		status = getStatus(ctx)
+	})
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/pgc/player/api/playurl?area=hk&ep_id=1")
+	handler(ctx)
+	if status == nil || !status.unauthenticated {
+		t.Fatalf("status = %+v, want unauthenticated", status)
+	}
+}
+
+func TestCheckVipOnly(t *testing.T) {
+	c := &Config{}
+	c.VipOnly = true
+	b := newTestBiliroaming(t, c)
+	for _, tc := range []struct {
+		name   string
+		status *userStatus
+		ok     bool
+	}{
+		{"unauthenticated", unauthenticatedStatus(), true},
+		{"vip", &userStatus{uid: 1, isLogin: true, isVip: true}, true},
+		{"not vip", &userStatus{uid: 1, isLogin: true}, false},
+	} {
+		if ok := b.checkVipOnly(&fasthttp.RequestCtx{}, tc.status); ok != tc.ok {
+			t.Errorf("%s: ok = %v, want %v", tc.name, ok, tc.ok)
+		}
+	}
+}
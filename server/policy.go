@@ -0,0 +1,79 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolicyMode 策略执行模式
+type PolicyMode string
+
+// PolicyMode
+const (
+	PolicyModeEnforce PolicyMode = "enforce"
+	PolicyModeReport  PolicyMode = "report"
+)
+
+// policy names
+const (
+	policyBlacklist  = "blacklist"
+	policyWhitelist  = "whitelist"
+	policyVipOnly    = "vipOnly"
+	policyVersion    = "version"
+	policyQuota      = "quota"
+	policyAccessRule = "accessRule"
+)
+
+var policyNames = []string{policyBlacklist, policyWhitelist, policyVipOnly, policyVersion, policyQuota, policyAccessRule}
+
+// accessRulePolicy policy of access rule group, e.g. accessRule.search,
+// fallback to accessRule if not set
+func accessRulePolicy(group string) string {
+	return policyAccessRule + "." + group
+}
+
+func validatePolicies(c *Config) error {
+	for name, mode := range c.Policies {
+		known := false
+		for _, n := range policyNames {
+			if n == name {
+				known = true
+				break
+			}
+		}
+		if strings.HasPrefix(name, policyAccessRule+".") {
+			_, known = c.AccessRules[strings.TrimPrefix(name, policyAccessRule+".")]
+		}
+		if !known {
+			return fmt.Errorf("unknown policy %q", name)
+		}
+		switch mode {
+		case "", PolicyModeEnforce, PolicyModeReport:
+		default:
+			return fmt.Errorf("unknown mode %q of policy %s", mode, name)
+		}
+	}
+	return nil
+}
+
+func (b *BiliroamingGo) getPolicyMode(policy string) PolicyMode {
+	if mode, ok := b.config.Policies[policy]; ok && mode != "" {
+		return mode
+	}
+	if parent, _, ok := strings.Cut(policy, "."); ok {
+		return b.getPolicyMode(parent)
+	}
+	return PolicyModeEnforce
+}
+
+// reportOnly record would-be rejection, return true if request should still be served,
+// report only hits are counted by biliroaming_policy_rejections_total and logged at debug level
+func (b *BiliroamingGo) reportOnly(policy string, subject string) bool {
+	mode := b.getPolicyMode(policy)
+	b.metrics.inc("biliroaming_policy_rejections_total", "policy", policy, "mode", string(mode))
+	if mode != PolicyModeReport {
+		return false
+	}
+	b.sugar.Debugf("Policy %s would reject %s (report only)", policy, subject)
+	return true
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+const userValueClientIP = "clientIP"
+
+// parseCIDRs parse CIDR list, single IP without prefix is allowed
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid CIDR '%s'", cidr)
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				nets = append(nets, &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)})
+			} else {
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+			}
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR '%s': %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *BiliroamingGo) isTrustedProxy(ip net.IP) bool {
+	return ip != nil && containsIP(b.trustedProxies, ip)
+}
+
+// resolveClientIP 获取真实 IP，仅信任来自受信代理的请求头
+func (b *BiliroamingGo) resolveClientIP(ctx *fasthttp.RequestCtx) net.IP {
+	remoteIP := ctx.RemoteIP()
+	if !b.isTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+
+	// only the header set by operator is trusted, others may be sent by client
+	if header := b.config.RealIPHeader; header != "" && !strings.EqualFold(header, fasthttp.HeaderXForwardedFor) {
+		if ip := net.ParseIP(string(bytes.TrimSpace(ctx.Request.Header.Peek(header)))); ip != nil {
+			return ip
+		}
+		return remoteIP
+	}
+
+	// walk from right to left, the first untrusted hop is the client
+	forwardedFor := strings.Split(string(ctx.Request.Header.Peek(fasthttp.HeaderXForwardedFor)), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwardedFor[i]))
+		if ip == nil {
+			break
+		}
+		if i == 0 || !b.isTrustedProxy(ip) {
+			return ip
+		}
+	}
+
+	return remoteIP
+}
+
+// setClientIP resolve client ip once and store it on request context
+func (b *BiliroamingGo) setClientIP(ctx *fasthttp.RequestCtx) {
+	ip := b.resolveClientIP(ctx)
+	ctx.SetUserValue(userValueClientIP, ip)
+}
+
+// getClientIP get client ip from request context
+func getClientIP(ctx *fasthttp.RequestCtx) net.IP {
+	if ip, ok := ctx.UserValue(userValueClientIP).(net.IP); ok {
+		return ip
+	}
+	return ctx.RemoteIP()
+}
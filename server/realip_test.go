@@ -0,0 +1,128 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := parseCIDRs([]string{"127.0.0.1", " ::1 ", "", "10.0.0.0/8", "2001:db8::/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nets) != 4 {
+		t.Fatalf("got %d nets, want 4", len(nets))
+	}
+	for _, tc := range []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"127.0.0.2", false},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"11.0.0.1", false},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+	} {
+		if got := containsIP(nets, net.ParseIP(tc.ip)); got != tc.want {
+			t.Errorf("contains %s = %v, want %v", tc.ip, got, tc.want)
+		}
+	}
+
+	for _, invalid := range []string{"localhost", "10.0.0.0/33", "1.2.3"} {
+		if _, err := parseCIDRs([]string{invalid}); err == nil {
+			t.Errorf("%q accepted", invalid)
+		}
+	}
+}
+
+func TestResolveClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		header  string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "untrusted peer spoofs headers",
+			header:  "X-Real-IP",
+			remote:  "203.0.113.1",
+			headers: map[string]string{"X-Real-IP": "1.1.1.1", "CF-Connecting-IP": "1.1.1.1", "X-Forwarded-For": "1.1.1.1"},
+			want:    "203.0.113.1",
+		},
+		{
+			name:    "trusted proxy configured header",
+			header:  "X-Real-IP",
+			remote:  "127.0.0.1",
+			headers: map[string]string{"X-Real-IP": "198.51.100.7"},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "trusted proxy ignores other headers",
+			header:  "X-Real-IP",
+			remote:  "127.0.0.1",
+			headers: map[string]string{"X-Real-IP": "198.51.100.7", "CF-Connecting-IP": "1.1.1.1", "X-Forwarded-For": "1.1.1.1"},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "trusted proxy without configured header",
+			header:  "X-Real-IP",
+			remote:  "127.0.0.1",
+			headers: map[string]string{"CF-Connecting-IP": "1.1.1.1"},
+			want:    "127.0.0.1",
+		},
+		{
+			name:    "forwarded for ignores cf header",
+			remote:  "127.0.0.1",
+			headers: map[string]string{"CF-Connecting-IP": "1.1.1.1", "X-Real-IP": "1.1.1.1", "X-Forwarded-For": "198.51.100.7"},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "forwarded for spoofed left hop",
+			remote:  "127.0.0.1",
+			headers: map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.7"},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "forwarded for skips trusted hops",
+			remote:  "127.0.0.1",
+			headers: map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.7, 10.0.0.2"},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "forwarded for invalid hop",
+			remote:  "127.0.0.1",
+			headers: map[string]string{"X-Forwarded-For": "1.1.1.1, garbage"},
+			want:    "127.0.0.1",
+		},
+		{
+			name:    "forwarded for from untrusted peer",
+			remote:  "203.0.113.1",
+			headers: map[string]string{"X-Forwarded-For": "1.1.1.1"},
+			want:    "203.0.113.1",
+		},
+	} {
+		c := &Config{RealIPHeader: tc.header}
+		b := newTestBiliroaming(t, c)
+		var err error
+		b.trustedProxies, err = parseCIDRs([]string{"127.0.0.1", "10.0.0.0/8"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := &fasthttp.Request{}
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Init(req, &net.TCPAddr{IP: net.ParseIP(tc.remote), Port: 12345}, nil)
+
+		if got := b.resolveClientIP(ctx); !got.Equal(net.ParseIP(tc.want)) {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
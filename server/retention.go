@@ -0,0 +1,65 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+)
+
+// RetentionMode 不活跃用户处理方式
+type RetentionMode string
+
+// RetentionMode
+const (
+	RetentionDelete    RetentionMode = "delete"
+	RetentionAnonymize RetentionMode = "anonymize"
+)
+
+// runRetention purge access keys and users inactive longer than retention days
+func (b *BiliroamingGo) runRetention() (int64, error) {
+	c := b.config.Retention
+	if c.InactiveDays <= 0 {
+		return 0, nil
+	}
+	duration := time.Duration(c.InactiveDays) * 24 * time.Hour
+
+	// access key and cookie are credentials, never kept
+	aff, err := b.db.PurgeInactiveAccessKeys(duration, c.BatchSize)
+	if err != nil {
+		return aff, err
+	}
+	affCookies, err := b.db.PurgeInactiveCookieSessions(duration, c.BatchSize)
+	aff += affCookies
+	if err != nil {
+		return aff, err
+	}
+
+	var affUsers int64
+	switch c.Mode {
+	case "", RetentionDelete:
+		affUsers, err = b.db.PurgeInactiveUsers(duration, c.BatchSize)
+	case RetentionAnonymize:
+		affUsers, err = b.db.AnonymizeInactiveUsers(duration, c.BatchSize)
+	default:
+		err = errors.New("unknown retention mode " + string(c.Mode))
+	}
+	return aff + affUsers, err
+}
+
+// forgetUser delete all stored data of user and evict caches on all instances
+func (b *BiliroamingGo) forgetUser(uid int64) (int64, error) {
+	aff, err := b.db.ForgetUser(uid)
+	if err != nil {
+		return aff, err
+	}
+	if b.sessionSecret != nil {
+		if err := b.revokeSessions(uid); err != nil {
+			return aff, err
+		}
+	}
+	b.recentCredentials.remove(uid)
+	b.evictUser(uid, database.InvalidationUserForget)
+	b.sugar.Infof("Forget user %d, %d rows deleted", uid, aff)
+	return aff, nil
+}
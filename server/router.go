@@ -0,0 +1,244 @@
+package server
+
+import (
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	userValueArgs   = "args"
+	userValueStatus = "status"
+	// userValueFailed set by writeErrorJSON
+	userValueFailed = "failed"
+)
+
+// middleware wrap handler, run in order of declaration
+type middleware func(next fasthttp.RequestHandler) fasthttp.RequestHandler
+
+func chain(h fasthttp.RequestHandler, mws ...middleware) fasthttp.RequestHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// router exact path router, routes can be disabled by config
+type router struct {
+	b        *BiliroamingGo
+	routes   map[string]fasthttp.RequestHandler
+	global   []middleware
+	notFound fasthttp.RequestHandler
+}
+
+func newRouter(b *BiliroamingGo, notFound fasthttp.RequestHandler) *router {
+	return &router{
+		b:        b,
+		routes:   make(map[string]fasthttp.RequestHandler),
+		notFound: notFound,
+	}
+}
+
+// use add middlewares to every request, including not found
+func (r *router) use(mws ...middleware) {
+	r.global = append(r.global, mws...)
+}
+
+// handle register route with name used by config and metrics
+func (r *router) handle(name string, path string, h fasthttp.RequestHandler, mws ...middleware) {
+	if enabled, ok := r.b.config.Routes[name]; ok && !enabled {
+		r.b.sugar.Debugf("Route %s %s disabled", name, path)
+		return
+	}
+	r.routes[path] = chain(h, append([]middleware{r.b.metricsMiddleware(name)}, mws...)...)
+}
+
+func (r *router) handler() fasthttp.RequestHandler {
+	return chain(func(ctx *fasthttp.RequestCtx) {
+		if h, ok := r.routes[string(ctx.Path())]; ok {
+			h(ctx)
+			return
+		}
+		r.notFound(ctx)
+	}, r.global...)
+}
+
+func (b *BiliroamingGo) recoverMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		defer func() {
+			if r := recover(); r != nil {
+				b.sugar.Errorf("Panic on %s: %v\n%s", ctx.Path(), r, debug.Stack())
+				b.metrics.inc("biliroaming_http_panics_total")
+				ctx.ResetBody()
+				writeErrorJSON(ctx, ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+			}
+		}()
+		next(ctx)
+	}
+}
+
+func (b *BiliroamingGo) clientIPMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.SetBytesKV([]byte("Server"), []byte(DEFAULT_NAME))
+		b.setClientIP(ctx)
+		next(ctx)
+	}
+}
+
+func (b *BiliroamingGo) loggingMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		startedAt := time.Now()
+		b.sugar.Debugf("%s %s from %s", ctx.Method(), ctx.Path(), getClientIP(ctx))
+		next(ctx)
+		b.sugar.Debugf("%s %s %d in %s", ctx.Method(), ctx.Path(), ctx.Response.StatusCode(), time.Since(startedAt))
+	}
+}
+
+// metricsMiddleware request count and total duration per route
+func (b *BiliroamingGo) metricsMiddleware(name string) middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			startedAt := time.Now()
+			next(ctx)
+			b.metrics.inc("biliroaming_http_requests_total", "route", name, "status", strconv.Itoa(ctx.Response.StatusCode()))
+			b.metrics.add("biliroaming_http_request_duration_milliseconds_total", time.Since(startedAt).Milliseconds(), "route", name)
+		}
+	}
+}
+
+// corsMiddleware answer preflight of web clients
+func corsMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if ctx.IsOptions() {
+			setDefaultHeaders(ctx)
+			ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			ctx.Response.Header.Set("Access-Control-Allow-Headers", string(ctx.Request.Header.Peek("Access-Control-Request-Headers")))
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+			return
+		}
+		next(ctx)
+	}
+}
+
+func (b *BiliroamingGo) versionMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if b.checkRoamingVer(ctx) {
+			next(ctx)
+		}
+	}
+}
+
+// webVersionMiddleware version check without requiring biliroaming header
+func (b *BiliroamingGo) webVersionMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if b.checkVersionPolicy(ctx, false) {
+			next(ctx)
+		}
+	}
+}
+
+func (b *BiliroamingGo) accessRuleMiddleware(group string) middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if b.checkAccessRule(ctx, group) {
+				next(ctx)
+			}
+		}
+	}
+}
+
+func (b *BiliroamingGo) searchLimiterMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if b.doCheckSearchLimiter(ctx) {
+			next(ctx)
+		}
+	}
+}
+
+func (b *BiliroamingGo) ipLimiterMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if b.doCheckIpLimiter(ctx) {
+			next(ctx)
+		}
+	}
+}
+
+// getArgs parse query arguments once per request
+func (b *BiliroamingGo) getArgs(ctx *fasthttp.RequestCtx) *biliArgs {
+	if args, ok := ctx.UserValue(userValueArgs).(*biliArgs); ok {
+		return args
+	}
+	args := b.processArgs(ctx, ctx.URI().QueryArgs())
+	ctx.SetUserValue(userValueArgs, args)
+	return args
+}
+
+// argsMiddleware area not empty override area of request
+func (b *BiliroamingGo) argsMiddleware(area string) middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			args := b.getArgs(ctx)
+			if area != "" {
+				args.area = area
+			}
+			next(ctx)
+		}
+	}
+}
+
+func (b *BiliroamingGo) signMiddleware(expect ClientType, required bool) middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			nonce, ok := b.verifySign(ctx, b.getArgs(ctx), expect, required)
+			if !ok {
+				return
+			}
+			if nonce == "" {
+				next(ctx)
+				return
+			}
+			// rejected, failed or panicked requests can be retried with the same sign
+			completed := false
+			defer func() {
+				if failed, _ := ctx.UserValue(userValueFailed).(bool); failed || !completed {
+					b.releaseNonce(nonce)
+				}
+			}()
+			next(ctx)
+			completed = true
+		}
+	}
+}
+
+// getStatus user status set by authMiddleware, never nil,
+// unauthenticated if area does not require auth
+func getStatus(ctx *fasthttp.RequestCtx) *userStatus {
+	if status, ok := ctx.UserValue(userValueStatus).(*userStatus); ok {
+		return status
+	}
+	return unauthenticatedStatus()
+}
+
+// authMiddleware auth when area requires, otherwise limit by ip if ipFallback
+func (b *BiliroamingGo) authMiddleware(ipFallback bool) middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			args := b.getArgs(ctx)
+			if b.getAuthByArea(args.area) {
+				ok, status := b.doAuth(ctx, args.accessKey, b.getClientPlatform(ctx, args.appkey), args.area, false)
+				if !ok {
+					return
+				}
+				ctx.SetUserValue(userValueStatus, status)
+			} else {
+				if ipFallback && !b.doCheckIpLimiter(ctx) {
+					return
+				}
+				ctx.SetUserValue(userValueStatus, unauthenticatedStatus())
+			}
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+)
+
+const jobHistorySize = 10
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrNotLeader   = errors.New("not leader")
+)
+
+// JobConfig 定时任务设置
+type JobConfig struct {
+	Enabled  *bool          `yaml:"enabled"`
+	Interval time.Duration  `yaml:"interval"`
+	Jitter   *time.Duration `yaml:"jitter"`
+}
+
+// jobFunc return rows affected
+type jobFunc func() (int64, error)
+
+type job struct {
+	name       string
+	enabled    bool
+	leaderOnly bool
+	interval   time.Duration
+	jitter     time.Duration
+	run        jobFunc
+	trigger    chan struct{}
+
+	mu       sync.Mutex
+	running  bool
+	runs     int64
+	failures int64
+	history  []entity.JobRun
+}
+
+type scheduler struct {
+	b        *BiliroamingGo
+	mu       sync.RWMutex
+	jobs     map[string]*job
+	isLeader func() (bool, error)
+}
+
+func newScheduler(b *BiliroamingGo) *scheduler {
+	return &scheduler{
+		b:    b,
+		jobs: make(map[string]*job),
+		isLeader: func() (bool, error) {
+			return b.leader.IsLeader()
+		},
+	}
+}
+
+// register add job with defaults, overridden by config
+func (s *scheduler) register(name string, enabled bool, leaderOnly bool, interval time.Duration, jitter time.Duration, run jobFunc) {
+	j := &job{
+		name:       name,
+		enabled:    enabled,
+		leaderOnly: leaderOnly,
+		interval:   interval,
+		jitter:     jitter,
+		run:        run,
+		trigger:    make(chan struct{}, 1),
+	}
+	if c, ok := s.b.config.Jobs[name]; ok && c != nil {
+		if c.Enabled != nil {
+			j.enabled = *c.Enabled
+		}
+		if c.Interval > 0 {
+			j.interval = c.Interval
+		}
+		if c.Jitter != nil && *c.Jitter >= 0 {
+			j.jitter = *c.Jitter
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = j
+}
+
+// start run jobs until ctx is done
+func (s *scheduler) start(ctx context.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, j := range s.jobs {
+		go s.loop(ctx, j)
+	}
+}
+
+func (j *job) nextDelay() time.Duration {
+	delay := j.interval
+	if j.jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(j.jitter)))
+	}
+	return delay
+}
+
+func (s *scheduler) loop(ctx context.Context, j *job) {
+	// first run right after start, spread by jitter
+	var delay time.Duration
+	if j.jitter > 0 {
+		delay = time.Duration(rand.Int63n(int64(j.jitter)))
+	}
+	for {
+		if !j.enabled {
+			select {
+			case <-ctx.Done():
+				return
+			case <-j.trigger:
+			}
+		} else {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			case <-j.trigger:
+				timer.Stop()
+			}
+		}
+		s.runJob(j)
+		delay = j.nextDelay()
+	}
+}
+
+// checkLeader record skipped run when leader only job can not run on this instance
+func (s *scheduler) checkLeader(j *job) bool {
+	if !j.leaderOnly {
+		return true
+	}
+	isLeader, err := s.isLeader()
+	if err != nil {
+		s.b.sugar.Error(err)
+	}
+	if isLeader {
+		return true
+	}
+
+	s.b.sugar.Debugf("Not leader, skip job %s", j.name)
+	s.b.metrics.inc("biliroaming_job_runs_total", "job", j.name, "result", "skipped")
+	run := entity.JobRun{
+		StartedAt: time.Now(),
+		Error:     ErrNotLeader.Error(),
+		Skipped:   true,
+	}
+	j.mu.Lock()
+	j.addHistory(run)
+	j.mu.Unlock()
+	return false
+}
+
+func (j *job) addHistory(run entity.JobRun) {
+	j.history = append([]entity.JobRun{run}, j.history...)
+	if len(j.history) > jobHistorySize {
+		j.history = j.history[:jobHistorySize]
+	}
+}
+
+func (s *scheduler) runJob(j *job) {
+	if !s.checkLeader(j) {
+		return
+	}
+
+	j.mu.Lock()
+	j.running = true
+	j.mu.Unlock()
+
+	s.b.sugar.Debugf("Running job %s", j.name)
+	startedAt := time.Now()
+	rows, err := j.run()
+	duration := time.Since(startedAt)
+
+	run := entity.JobRun{
+		StartedAt:    startedAt,
+		DurationMs:   duration.Milliseconds(),
+		RowsAffected: rows,
+	}
+	result := "success"
+	if err != nil {
+		s.b.sugar.Errorf("Job %s: %v", j.name, err)
+		run.Error = err.Error()
+		result = "failure"
+	} else {
+		s.b.sugar.Debugf("Job %s affected %d rows in %s", j.name, rows, duration)
+	}
+
+	j.mu.Lock()
+	j.running = false
+	j.runs++
+	if err != nil {
+		j.failures++
+	}
+	j.addHistory(run)
+	j.mu.Unlock()
+
+	s.b.metrics.inc("biliroaming_job_runs_total", "job", j.name, "result", result)
+	s.b.metrics.set("biliroaming_job_last_duration_seconds", duration.Seconds(), "job", j.name)
+	s.b.metrics.set("biliroaming_job_last_rows_affected", float64(rows), "job", j.name)
+	s.b.metrics.set("biliroaming_job_last_run_timestamp_seconds", float64(startedAt.Unix()), "job", j.name)
+}
+
+// trigger run job as soon as possible, leader only job is rejected on other instances
+func (s *scheduler) trigger(name string) error {
+	s.mu.RLock()
+	j, ok := s.jobs[name]
+	s.mu.RUnlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	if !s.checkLeader(j) {
+		return ErrNotLeader
+	}
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+		// already queued
+	}
+	return nil
+}
+
+func (s *scheduler) status() []entity.JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]entity.JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		statuses = append(statuses, entity.JobStatus{
+			Name:       j.name,
+			Enabled:    j.enabled,
+			LeaderOnly: j.leaderOnly,
+			Interval:   j.interval.String(),
+			Jitter:     j.jitter.String(),
+			Running:    j.running,
+			Runs:       j.runs,
+			Failures:   j.failures,
+			History:    append([]entity.JobRun{}, j.history...),
+		})
+		j.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, k int) bool {
+		return statuses[i].Name < statuses[k].Name
+	})
+	return statuses
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTriggerLeaderOnlyJob(t *testing.T) {
+	b := newTestBiliroaming(t, &Config{})
+	s := newScheduler(b)
+	leader := false
+	s.isLeader = func() (bool, error) {
+		return leader, nil
+	}
+	ran := 0
+	s.register("cleanup", true, true, time.Hour, 0, func() (int64, error) {
+		ran++
+		return 0, nil
+	})
+
+	if err := s.trigger("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("trigger missing job = %v, want ErrJobNotFound", err)
+	}
+	if err := s.trigger("cleanup"); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("trigger on non-leader = %v, want ErrNotLeader", err)
+	}
+	status := s.status()[0]
+	if len(status.History) != 1 || !status.History[0].Skipped {
+		t.Fatalf("history = %+v, want one skipped run", status.History)
+	}
+	if status.Runs != 0 {
+		t.Errorf("runs = %d, want 0", status.Runs)
+	}
+
+	leader = true
+	if err := s.trigger("cleanup"); err != nil {
+		t.Fatal(err)
+	}
+	s.runJob(s.jobs["cleanup"])
+	if ran != 1 {
+		t.Errorf("ran %d times, want 1", ran)
+	}
+	if status := s.status()[0]; len(status.History) != 2 || status.History[0].Skipped {
+		t.Errorf("history = %+v, want run after skip", status.History)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package server
 
 import (
 	"encoding/json"
@@ -65,17 +65,7 @@ func (b *BiliroamingGo) addWebSearchAds(data []byte) ([]byte, error) {
 }
 
 func (b *BiliroamingGo) handleAndroidSearch(ctx *fasthttp.RequestCtx) {
-	if !b.checkRoamingVer(ctx) {
-		return
-	}
-
-	if !b.searchLimiter.Allow() {
-		writeErrorJSON(ctx, ERROR_CODE_TOO_MANY_REQUESTS, MSG_ERROR_TOO_MANY_REQUESTS)
-		return
-	}
-
-	queryArgs := ctx.URI().QueryArgs()
-	args := b.processArgs(queryArgs)
+	args := b.getArgs(ctx)
 
 	if args.area == "" || args.area == "th" {
 		writeErrorJSON(ctx, ERROR_CODE_GEO_RESTRICED, MSG_ERROR_GEO_RESTRICTED)
@@ -89,7 +79,7 @@ func (b *BiliroamingGo) handleAndroidSearch(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	clientType := getClientPlatform(ctx, args.appkey)
+	clientType := b.getClientPlatform(ctx, args.appkey)
 
 	v := url.Values{}
 	v.Set("access_key", args.accessKey)
@@ -98,11 +88,11 @@ func (b *BiliroamingGo) handleAndroidSearch(ctx *fasthttp.RequestCtx) {
 	v.Set("highlight", "1")
 	v.Set("keyword", args.keyword)
 	v.Set("type", strconv.Itoa(args.aType))
-	v.Set("mobi_app", clientType.String())
+	b.appKeys.setMobiApp(v, clientType)
 	v.Set("platform", "android")
 	v.Set("pn", strconv.Itoa(args.pn))
 
-	params, err := SignParams(v, clientType)
+	params, err := b.SignParams(v, clientType)
 	if err != nil {
 		b.processError(ctx, err)
 		return
@@ -161,19 +151,7 @@ func (b *BiliroamingGo) handleAndroidSearch(ctx *fasthttp.RequestCtx) {
 }
 
 func (b *BiliroamingGo) handleBstarAndroidSearch(ctx *fasthttp.RequestCtx) {
-	if !b.checkRoamingVer(ctx) {
-		return
-	}
-
-	if !b.searchLimiter.Allow() {
-		writeErrorJSON(ctx, ERROR_CODE_TOO_MANY_REQUESTS, MSG_ERROR_TOO_MANY_REQUESTS)
-		return
-	}
-
-	queryArgs := ctx.URI().QueryArgs()
-	args := b.processArgs(queryArgs)
-
-	args.area = "th"
+	args := b.getArgs(ctx)
 
 	client := b.getClientByArea(args.area)
 
@@ -184,15 +162,15 @@ func (b *BiliroamingGo) handleBstarAndroidSearch(ctx *fasthttp.RequestCtx) {
 
 	v := url.Values{}
 	v.Set("access_key", args.accessKey)
-	v.Set("build", "1080003")
+	b.appKeys.setBuild(v, ClientTypeBstarA)
 	v.Set("keyword", args.keyword)
-	v.Set("platform", "android")
+	b.appKeys.setPlatform(v, ClientTypeBstarA)
 	v.Set("s_locale", "zh_SG")
 	v.Set("type", strconv.Itoa(args.aType))
-	v.Set("mobi_app", "bstar_a")
+	b.appKeys.setMobiApp(v, ClientTypeBstarA)
 	v.Set("pn", strconv.Itoa(args.pn))
 
-	params, err := SignParams(v, ClientTypeBstarA)
+	params, err := b.SignParams(v, ClientTypeBstarA)
 	if err != nil {
 		b.processError(ctx, err)
 		return
@@ -251,17 +229,7 @@ func (b *BiliroamingGo) handleBstarAndroidSearch(ctx *fasthttp.RequestCtx) {
 }
 
 func (b *BiliroamingGo) handleWebSearch(ctx *fasthttp.RequestCtx) {
-	if !b.checkRoamingVer(ctx) {
-		return
-	}
-
-	if !b.searchLimiter.Allow() {
-		writeErrorJSON(ctx, ERROR_CODE_TOO_MANY_REQUESTS, MSG_ERROR_TOO_MANY_REQUESTS)
-		return
-	}
-
-	queryArgs := ctx.URI().QueryArgs()
-	args := b.processArgs(queryArgs)
+	args := b.getArgs(ctx)
 
 	if args.area == "" || args.area == "th" {
 		writeErrorJSON(ctx, ERROR_CODE_GEO_RESTRICED, MSG_ERROR_GEO_RESTRICTED)
@@ -281,7 +249,7 @@ func (b *BiliroamingGo) handleWebSearch(ctx *fasthttp.RequestCtx) {
 	v.Set("keyword", args.keyword)
 	v.Set("page", strconv.Itoa(args.page))
 
-	params, err := SignParams(v, ClientTypeAndroid)
+	params, err := b.SignParams(v, ClientTypeAndroid)
 	if err != nil {
 		b.processError(ctx, err)
 		return
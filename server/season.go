@@ -1,4 +1,4 @@
-package main
+package server
 
 import (
 	"database/sql"
@@ -18,7 +18,7 @@ import (
 )
 
 func (b *BiliroamingGo) checkSeasonAreaCache(seasonId int64, area database.Area) bool {
-	if cache, err := b.db.GetSeasonAreaCache(seasonId); err == nil {
+	if cache, err := b.getSeasonAreaCache(seasonId); err == nil {
 		switch area {
 		case database.AreaCN:
 			if cache.CN.Valid && !cache.CN.Bool {
@@ -46,7 +46,7 @@ func (b *BiliroamingGo) checkSeasonAreaCache(seasonId int64, area database.Area)
 func (b *BiliroamingGo) updateSeasonCache(data []byte, seasonId int64, area database.Area) error {
 	if available, err := isAvailableResponse(data); err != nil {
 		return err
-	} else if err := b.db.InsertOrUpdateSeasonAreaCache(seasonId, area, available); err != nil {
+	} else if err := b.setSeasonAreaCache(seasonId, area, available); err != nil {
 		return err
 	}
 	return nil
@@ -67,13 +67,13 @@ func (b *BiliroamingGo) insertSeasonCache(data []byte, isVIP bool) error {
 		return nil
 	}
 
-	if err := b.db.InsertOrUpdateSeasonAreaCache(seasonResult.Result.SeasonID, database.AreaTH, true); err != nil {
+	if err := b.setSeasonAreaCache(seasonResult.Result.SeasonID, database.AreaTH, true); err != nil {
 		b.sugar.Error(err)
 	}
 
 	for _, mdl := range seasonResult.Result.Modules {
 		for _, ep := range mdl.Data.Episodes {
-			if err := b.db.InsertOrUpdateEpisodeAreaCache(ep.ID, database.AreaTH, true); err != nil {
+			if err := b.setEpisodeAreaCache(ep.ID, database.AreaTH, true); err != nil {
 				b.sugar.Error()
 			}
 			if err := b.db.InsertOrUpdateTHSeasonEpisodeCache(ep.ID, seasonResult.Result.SeasonID); err != nil {
@@ -182,14 +182,7 @@ func (b *BiliroamingGo) replaceSeason(ctx *fasthttp.RequestCtx, seasonResult []b
 }
 
 func (b *BiliroamingGo) handleBstarAndroidSeason(ctx *fasthttp.RequestCtx) {
-	if !b.checkRoamingVer(ctx) {
-		return
-	}
-
-	queryArgs := ctx.URI().QueryArgs()
-	args := b.processArgs(queryArgs)
-
-	args.area = "th"
+	args := b.getArgs(ctx)
 
 	client := b.getClientByArea(args.area)
 
@@ -206,9 +199,6 @@ func (b *BiliroamingGo) handleBstarAndroidSeason(ctx *fasthttp.RequestCtx) {
 	}
 
 	if b.getAuthByArea(args.area) {
-		if ok, _ := b.doAuth(ctx, args.accessKey, getClientPlatform(ctx, args.appkey), args.area, false); !ok {
-			return
-		}
 		if args.seasonId != 0 {
 			seasonCache, err := b.db.GetTHSeasonCache(args.seasonId, false)
 			if err == nil && len(seasonCache.Data) > 0 && seasonCache.UpdatedAt.After(time.Now().Add(-b.config.Cache.THSeason)) {
@@ -239,7 +229,7 @@ func (b *BiliroamingGo) handleBstarAndroidSeason(ctx *fasthttp.RequestCtx) {
 
 	v := url.Values{}
 	v.Set("access_key", args.accessKey)
-	v.Set("build", "1080003")
+	b.appKeys.setBuild(v, ClientTypeBstarA)
 	v.Set("s_locale", "zh_SG")
 	if args.seasonId != 0 {
 		v.Set("season_id", strconv.FormatInt(args.seasonId, 10))
@@ -247,9 +237,9 @@ func (b *BiliroamingGo) handleBstarAndroidSeason(ctx *fasthttp.RequestCtx) {
 	if args.epId != 0 {
 		v.Set("ep_id", strconv.FormatInt(args.epId, 10))
 	}
-	v.Set("mobi_app", "bstar_a")
+	b.appKeys.setMobiApp(v, ClientTypeBstarA)
 
-	params, err := SignParams(v, ClientTypeBstarA)
+	params, err := b.SignParams(v, ClientTypeBstarA)
 	if err != nil {
 		b.processError(ctx, err)
 		return
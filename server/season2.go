@@ -1,4 +1,4 @@
-package main
+package server
 
 import (
 	"database/sql"
@@ -136,14 +136,7 @@ func (b *BiliroamingGo) addCustomSubSeason2(ctx *fasthttp.RequestCtx, seasonResu
 }
 
 func (b *BiliroamingGo) handleBstarAndroidSeason2(ctx *fasthttp.RequestCtx) {
-	if !b.checkRoamingVer(ctx) {
-		return
-	}
-
-	queryArgs := ctx.URI().QueryArgs()
-	args := b.processArgs(queryArgs)
-
-	args.area = "th"
+	args := b.getArgs(ctx)
 
 	client := b.getClientByArea(args.area)
 
@@ -153,9 +146,6 @@ func (b *BiliroamingGo) handleBstarAndroidSeason2(ctx *fasthttp.RequestCtx) {
 	}
 
 	if b.getAuthByArea(args.area) {
-		if ok, _ := b.doAuth(ctx, args.accessKey, getClientPlatform(ctx, args.appkey), args.area, false); !ok {
-			return
-		}
 		if args.seasonId != 0 {
 			season2Cache, err := b.db.GetTHSeason2Cache(args.seasonId, false)
 			if err == nil && len(season2Cache.Data) > 0 && season2Cache.UpdatedAt.After(time.Now().Add(-b.config.Cache.THSeason)) {
@@ -186,7 +176,7 @@ func (b *BiliroamingGo) handleBstarAndroidSeason2(ctx *fasthttp.RequestCtx) {
 
 	v := url.Values{}
 	v.Set("access_key", args.accessKey)
-	v.Set("platform", "android")
+	b.appKeys.setPlatform(v, ClientTypeBstarA)
 	v.Set("s_locale", "zh_SG")
 	if args.seasonId != 0 {
 		v.Set("season_id", strconv.FormatInt(args.seasonId, 10))
@@ -195,7 +185,7 @@ func (b *BiliroamingGo) handleBstarAndroidSeason2(ctx *fasthttp.RequestCtx) {
 		v.Set("ep_id", strconv.FormatInt(args.epId, 10))
 	}
 
-	params, err := SignParams(v, ClientTypeBstarA)
+	params, err := b.SignParams(v, ClientTypeBstarA)
 	if err != nil {
 		b.processError(ctx, err)
 		return
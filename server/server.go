@@ -0,0 +1,445 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/lib/pq"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	MAJOR    = "2"
+	MINOR    = "27"
+	REVISION = "0"
+
+	VERSION = MAJOR + "." + MINOR + "." + REVISION
+
+	DEFAULT_NAME = "biliroaming-go-server/" + VERSION
+)
+
+type accessKey struct {
+	uid         int64
+	isLogin     bool
+	isVip       bool
+	isBlacklist bool
+	isWhitelist bool
+	banUntil    time.Time
+	timestamp   time.Time
+}
+
+// BiliroamingGo ...
+type BiliroamingGo struct {
+	config         *Config
+	trustedProxies []*net.IPNet
+	geoIP          *geoIP
+	accessRules    map[string]*accessRule
+	uidLimiter     limiterBackend
+	searchLimiter  limiterBackend
+	ipLimiter      limiterBackend
+	authCache      authBackend
+	areas          *areaCaches
+	nonces         nonceBackend
+	appKeys        *appKeyRegistry
+	scheduler      *scheduler
+	metrics        *metrics
+	adminToken     string
+	ctx            context.Context
+	logger         *zap.Logger
+	sugar          *zap.SugaredLogger
+
+	sessionSecret      []byte
+	sessionRevocations *sessionRevocations
+	recentCredentials  *recentCredentials
+
+	cnClient      *fasthttp.Client
+	hkClient      *fasthttp.Client
+	twClient      *fasthttp.Client
+	thClient      *fasthttp.Client
+	defaultClient *fasthttp.Client
+
+	HealthPlayUrlCN *entity.Health
+	HealthPlayUrlHK *entity.Health
+	HealthPlayUrlTW *entity.Health
+	HealthPlayUrlTH *entity.Health
+
+	HealthSeasonTH *entity.Health
+
+	HealthSearchCN *entity.Health
+	HealthSearchHK *entity.Health
+	HealthSearchTW *entity.Health
+	HealthSearchTH *entity.Health
+
+	db                   *database.DbHelper
+	leader               *database.LeaderElector
+	invalidationListener *pq.Listener
+}
+
+func (b *BiliroamingGo) getKey(key string) (*accessKey, bool) {
+	return b.authCache.get(key)
+}
+
+func (b *BiliroamingGo) deleteKey(key string) {
+	b.authCache.delete(key)
+}
+
+func (b *BiliroamingGo) setKey(key string, status *userStatus) {
+	b.authCache.set(key, &accessKey{
+		uid:         status.uid,
+		isLogin:     status.isLogin,
+		isVip:       status.isVip,
+		isBlacklist: status.isBlacklist,
+		isWhitelist: status.isWhitelist,
+		banUntil:    status.banUntil,
+		timestamp:   time.Now(),
+	})
+}
+
+func getDbPassword(c *Config) (string, error) {
+	pgPassword := c.PostgreSQL.Password
+	if c.PostgreSQL.PasswordFile != "" {
+		data, err := os.ReadFile(c.PostgreSQL.PasswordFile)
+		if err != nil {
+			return "", err
+		}
+		if len(data) > 0 {
+			pgPassword = string(data)
+		}
+	}
+	return pgPassword, nil
+}
+
+func getAccessKeySecret(c *Config) (string, error) {
+	secret := c.AccessKeyHash.Secret
+	if c.AccessKeyHash.SecretFile != "" {
+		data, err := os.ReadFile(c.AccessKeyHash.SecretFile)
+		if err != nil {
+			return "", err
+		}
+		if len(data) > 0 {
+			secret = strings.TrimSpace(string(data))
+		}
+	}
+	return secret, nil
+}
+
+// Server embeddable biliroaming server
+type Server struct {
+	b       *BiliroamingGo
+	routes  []customRoute
+	handler fasthttp.RequestHandler
+}
+
+type customRoute struct {
+	name    string
+	path    string
+	handler fasthttp.RequestHandler
+}
+
+type options struct {
+	logger    *zap.Logger
+	authStore AuthStore
+	routes    []customRoute
+}
+
+// Option optional settings of New
+type Option func(o *options)
+
+// WithLogger use logger instead of the one created from config debug, credentials are still redacted
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = logger.WithOptions(redactOption())
+	}
+}
+
+// WithAuthStore use store as auth cache instead of shared state backend
+func WithAuthStore(store AuthStore) Option {
+	return func(o *options) {
+		o.authStore = store
+	}
+}
+
+// WithRoute register handler on path, name is used by config routes and metrics
+func WithRoute(name string, path string, handler fasthttp.RequestHandler) Option {
+	return func(o *options) {
+		o.routes = append(o.routes, customRoute{name: name, path: path, handler: handler})
+	}
+}
+
+// New init server with config, connect database and run migrations
+func New(cfg Config, opts ...Option) (*Server, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	c := &cfg
+	if o.logger == nil {
+		logger, err := NewLogger(c.Debug)
+		if err != nil {
+			return nil, err
+		}
+		o.logger = logger
+	}
+
+	b := &BiliroamingGo{
+		config: c,
+		ctx:    context.Background(),
+		logger: o.logger,
+		sugar:  o.logger.Sugar(),
+
+		HealthPlayUrlCN: newHealth(),
+		HealthPlayUrlHK: newHealth(),
+		HealthPlayUrlTW: newHealth(),
+		HealthPlayUrlTH: newHealth(),
+
+		HealthSeasonTH: newHealth(),
+
+		HealthSearchCN: newHealth(),
+		HealthSearchHK: newHealth(),
+		HealthSearchTW: newHealth(),
+		HealthSearchTH: newHealth(),
+
+		metrics:           newMetrics(),
+		recentCredentials: newRecentCredentials(),
+	}
+
+	b.areas = newAreaCaches(b.metrics)
+
+	var err error
+	b.trustedProxies, err = parseCIDRs(c.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	b.appKeys, err = newAppKeyRegistry(c.AppKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePolicies(c); err != nil {
+		return nil, err
+	}
+
+	if err := b.initAccessRules(c); err != nil {
+		return nil, err
+	}
+
+	// release resources opened so far if init fails
+	ok := false
+	defer func() {
+		if ok {
+			return
+		}
+		b.geoIP.Close()
+		if b.db != nil {
+			if err := b.db.Close(); err != nil {
+				b.sugar.Error(err)
+			}
+		}
+	}()
+
+	b.adminToken, err = getAdminToken(c)
+	if err != nil {
+		return nil, err
+	}
+
+	b.initProxy(b.config)
+
+	pgPassword, err := getDbPassword(c)
+	if err != nil {
+		return nil, err
+	}
+
+	keySecret, err := getAccessKeySecret(c)
+	if err != nil {
+		return nil, err
+	}
+	if keySecret == "" {
+		return nil, errors.New("accessKeyHash.secret or accessKeyHash.secretFile is required, generate one with `openssl rand -hex 32`")
+	}
+
+	b.db, err = database.NewDBConnection(&database.Config{
+		Host:      c.PostgreSQL.Host,
+		User:      c.PostgreSQL.User,
+		Password:  pgPassword,
+		DBName:    c.PostgreSQL.DBName,
+		Port:      c.PostgreSQL.Port,
+		Debug:     c.Debug,
+		KeySecret: []byte(keySecret),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.initSharedState(c); err != nil {
+		return nil, err
+	}
+	if o.authStore != nil {
+		b.authCache = &externalAuthStore{store: o.authStore}
+	}
+
+	if err := b.initSession(c); err != nil {
+		return nil, err
+	}
+
+	b.leader = b.db.NewLeaderElector(database.LeaderLockCleanup)
+
+	b.scheduler = newScheduler(b)
+	b.registerJobs()
+
+	s := &Server{b: b, routes: o.routes}
+	s.handler = s.newHandler()
+	ok = true
+	return s, nil
+}
+
+// Handler request handler of all routes, serve it with own server after Start
+func (s *Server) Handler() fasthttp.RequestHandler {
+	return s.handler
+}
+
+// ForgetUser delete all stored data of user, return deleted rows
+func (s *Server) ForgetUser(uid int64) (int64, error) {
+	return s.b.forgetUser(uid)
+}
+
+// HashPlainKeys convert plain access keys stored by older versions again, done once at startup already
+func (s *Server) HashPlainKeys() (int64, error) {
+	return s.b.db.HashPlainKeys()
+}
+
+// Start listen invalidation events and start background jobs until ctx is done,
+// without listening on port, call once when serving Handler by own server
+func (s *Server) Start(ctx context.Context) error {
+	if err := s.b.initInvalidation(); err != nil {
+		return err
+	}
+	s.b.scheduler.start(ctx)
+	return nil
+}
+
+// Run Start and listen on config port until ctx is done
+func (s *Server) Run(ctx context.Context) error {
+	b := s.b
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := s.Start(ctx); err != nil {
+		return err
+	}
+
+	srv := &fasthttp.Server{Handler: s.handler}
+	errCh := make(chan error, 1)
+	go func() {
+		b.sugar.Infof("Listening on :%d ...", b.config.Port)
+		errCh <- srv.ListenAndServe(":" + strconv.Itoa(b.config.Port))
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	b.sugar.Info("Shutting down ...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	return srv.ShutdownWithContext(shutdownCtx)
+}
+
+// Close release geoip databases, invalidation listener, leader lock and database
+func (s *Server) Close() {
+	b := s.b
+	b.geoIP.Close()
+	if b.invalidationListener != nil {
+		if err := b.invalidationListener.Close(); err != nil {
+			b.sugar.Error(err)
+		}
+		b.invalidationListener = nil
+	}
+	if b.leader != nil {
+		if err := b.leader.Release(); err != nil {
+			b.sugar.Error(err)
+		}
+	}
+	if err := b.db.Close(); err != nil {
+		b.sugar.Error(err)
+	}
+}
+
+// newHandler register built-in routes, then custom routes
+func (s *Server) newHandler() fasthttp.RequestHandler {
+	b, c := s.b, s.b.config
+
+	fs := &fasthttp.FS{
+		Root:               "html",
+		IndexNames:         []string{"index.html"},
+		GenerateIndexPages: true,
+		Compress:           true,
+		AcceptByteRange:    false,
+		PathNotFound:       processNotFound,
+		// PathRewrite:        fasthttp.NewVHostPathRewriter(0),
+	}
+	fsHandler := fs.NewRequestHandler()
+
+	r := newRouter(b, fsHandler)
+	r.use(b.recoverMiddleware, b.clientIPMiddleware, b.loggingMiddleware)
+
+	// web
+	r.handle("webPlayUrl", "/pgc/player/web/playurl", b.handlePlayURL(webPlayUrlPlatform),
+		corsMiddleware, b.accessRuleMiddleware("playurl"), b.argsMiddleware(""), b.signMiddleware("", c.SignCheck.Web), b.authMiddleware(true))
+	r.handle("webSearch", "/x/web-interface/search/type", b.handleWebSearch,
+		corsMiddleware, b.webVersionMiddleware, b.accessRuleMiddleware("search"), b.searchLimiterMiddleware, b.argsMiddleware(""))
+
+	// android
+	r.handle("androidPlayUrl", "/pgc/player/api/playurl", b.handlePlayURL(androidPlayUrlPlatform),
+		b.versionMiddleware, b.accessRuleMiddleware("playurl"), b.argsMiddleware(""), b.signMiddleware("", true), b.authMiddleware(true))
+	r.handle("androidSearch", "/x/v2/search/type", b.handleAndroidSearch,
+		b.versionMiddleware, b.accessRuleMiddleware("search"), b.searchLimiterMiddleware, b.argsMiddleware(""))
+
+	// bstar android
+	r.handle("bstarPlayUrl", "/intl/gateway/v2/ogv/playurl", b.handlePlayURL(bstarPlayUrlPlatform),
+		b.versionMiddleware, b.accessRuleMiddleware("playurl"), b.argsMiddleware("th"), b.signMiddleware(ClientTypeBstarA, true), b.authMiddleware(true))
+	r.handle("bstarSearch", "/intl/gateway/v2/app/search/type", b.handleBstarAndroidSearch,
+		b.versionMiddleware, b.accessRuleMiddleware("search"), b.searchLimiterMiddleware, b.argsMiddleware("th"))
+	r.handle("bstarSeason", "/intl/gateway/v2/ogv/view/app/season", b.handleBstarAndroidSeason,
+		b.versionMiddleware, b.accessRuleMiddleware("season"), b.argsMiddleware("th"), b.authMiddleware(false))
+	r.handle("bstarSeason2", "/intl/gateway/v2/ogv/view/app/season2", b.handleBstarAndroidSeason2,
+		b.versionMiddleware, b.accessRuleMiddleware("season"), b.argsMiddleware("th"), b.authMiddleware(false))
+	r.handle("bstarSubtitle", "/intl/gateway/v2/app/subtitle", b.handleBstarAndroidSubtitle,
+		b.versionMiddleware, b.accessRuleMiddleware("subtitle"), b.argsMiddleware("th"))
+	r.handle("bstarEpisode", "/intl/gateway/v2/ogv/view/app/episode", b.handleBstarEpisode,
+		b.versionMiddleware, b.accessRuleMiddleware("episode"), b.argsMiddleware("th"))
+
+	// custom
+	r.handle("health", "/api/health", b.handleApiHealth)
+	if b.sessionSecret != nil {
+		r.handle("session", "/api/session", b.handleSession, b.accessRuleMiddleware("session"), b.ipLimiterMiddleware)
+	}
+	r.handle("me", "/api/me", b.handleMe, b.accessRuleMiddleware("me"), b.ipLimiterMiddleware)
+
+	// admin
+	r.handle("adminJobs", "/api/admin/jobs", b.handleAdminJobs)
+	r.handle("adminRunJob", "/api/admin/jobs/run", b.handleAdminRunJob)
+	r.handle("adminRevokeSessions", "/api/admin/sessions/revoke", b.handleAdminRevokeSessions)
+	r.handle("adminForgetUser", "/api/admin/users/forget", b.handleAdminForgetUser)
+	r.handle("adminPurgeCache", "/api/admin/cache/purge", b.handleAdminPurgeCache)
+	r.handle("adminMetrics", "/api/admin/metrics", b.handleAdminMetrics)
+
+	// custom routes registered last, same path replace built-in route
+	for _, route := range s.routes {
+		r.handle(route.name, route.path, route.handler)
+	}
+
+	return fasthttp.TimeoutHandler(r.handler(), 15*time.Second, fasthttp.StatusMessage(fasthttp.StatusRequestTimeout))
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestHandlerClient serve handler of server in memory
+func newTestHandlerClient(t *testing.T, s *Server) *fasthttp.Client {
+	t.Helper()
+	ln := fasthttputil.NewInmemoryListener()
+	srv := &fasthttp.Server{Handler: s.Handler()}
+	go srv.Serve(ln)
+	t.Cleanup(func() {
+		srv.Shutdown()
+	})
+	return &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) {
+			return ln.Dial()
+		},
+	}
+}
+
+func testGet(t *testing.T, client *fasthttp.Client, uri string) *fasthttp.Response {
+	t.Helper()
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("http://biliroaming.test" + uri)
+	resp := &fasthttp.Response{}
+	if err := client.Do(req, resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestHandlerRoutes(t *testing.T) {
+	c := &Config{}
+	c.Routes = map[string]bool{"adminMetrics": false}
+	s := &Server{
+		b: newTestBiliroaming(t, c),
+		routes: []customRoute{{name: "ping", path: "/ping", handler: func(ctx *fasthttp.RequestCtx) {
+			ctx.WriteString("pong")
+		}}},
+	}
+	s.handler = s.newHandler()
+	client := newTestHandlerClient(t, s)
+
+	if resp := testGet(t, client, "/ping"); string(resp.Body()) != "pong" {
+		t.Errorf("custom route body = %q", resp.Body())
+	}
+
+	resp := testGet(t, client, "/api/me")
+	me := &entity.MeResponse{}
+	if err := easyjson.Unmarshal(resp.Body(), me); err != nil {
+		t.Fatal(err)
+	}
+	if me.Code != ERROR_CODE_AUTH_NOT_LOGIN {
+		t.Errorf("/api/me without credential code = %d, want %d", me.Code, ERROR_CODE_AUTH_NOT_LOGIN)
+	}
+
+	if resp := testGet(t, client, "/api/admin/metrics"); resp.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("disabled route status = %d, want 404", resp.StatusCode())
+	}
+}
+
+func TestWithLoggerRedacts(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	o := &options{}
+	WithLogger(zap.New(core))(o)
+	// wrapped only once
+	WithLogger(o.logger)(o)
+
+	o.logger.Sugar().Infof("isAuth 1 %s", "0123456789abcdef0123456789abcdef")
+	o.logger.Info("request", zap.String("uri", "/pgc/player/api/playurl?access_key=secret&ep_id=1"))
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if msg := entries[0].Message; msg != "isAuth 1 "+redactedValue {
+		t.Errorf("message = %q", msg)
+	}
+	if uri := entries[1].ContextMap()["uri"]; uri != "/pgc/player/api/playurl?access_key="+redactedValue+"&ep_id=1" {
+		t.Errorf("uri = %q", uri)
+	}
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+)
+
+const defaultSessionTTL = 6 * time.Hour
+
+// session token group
+const (
+	sessionGroupUser      = "user"
+	sessionGroupWhitelist = "whitelist"
+)
+
+var (
+	ErrSessionInvalid = errors.New("invalid session token")
+	ErrSessionExpired = errors.New("session token expired")
+	ErrSessionRevoked = errors.New("session token revoked")
+)
+
+// sessionRevocations local copy of session_revocations table
+type sessionRevocations struct {
+	mu     sync.RWMutex
+	before map[int64]time.Time
+}
+
+func newSessionRevocations() *sessionRevocations {
+	return &sessionRevocations{
+		before: make(map[int64]time.Time),
+	}
+}
+
+func (r *sessionRevocations) isRevoked(uid int64, issuedAt time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	before, ok := r.before[uid]
+	return ok && !issuedAt.After(before)
+}
+
+func (r *sessionRevocations) set(uid int64, before time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if before.After(r.before[uid]) {
+		r.before[uid] = before
+	}
+}
+
+func (r *sessionRevocations) replace(before map[int64]time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.before = before
+}
+
+func getSessionSecret(c *Config) (string, error) {
+	secret := c.SessionToken.Secret
+	if c.SessionToken.SecretFile != "" {
+		data, err := os.ReadFile(c.SessionToken.SecretFile)
+		if err != nil {
+			return "", err
+		}
+		if len(data) > 0 {
+			secret = strings.TrimSpace(string(data))
+		}
+	}
+	return secret, nil
+}
+
+// initSession session token is optional, secret required when enabled
+func (b *BiliroamingGo) initSession(c *Config) error {
+	b.sessionRevocations = newSessionRevocations()
+	if !c.SessionToken.Enabled {
+		return nil
+	}
+	secret, err := getSessionSecret(c)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return errors.New("session token secret is empty")
+	}
+	b.sessionSecret = []byte(secret)
+	return b.syncSessionRevocations()
+}
+
+func (b *BiliroamingGo) getSessionTTL() time.Duration {
+	if b.config.SessionToken.TTL > 0 {
+		return b.config.SessionToken.TTL
+	}
+	return defaultSessionTTL
+}
+
+func (b *BiliroamingGo) signSession(payload string) string {
+	mac := hmac.New(sha256.New, b.sessionSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// issueSession token format: base64url(claims) "." base64url(hmac)
+func (b *BiliroamingGo) issueSession(status *userStatus) (string, *entity.SessionClaims, error) {
+	now := time.Now()
+	claims := &entity.SessionClaims{
+		UID:          status.uid,
+		IsVip:        status.isVip,
+		Group:        getUserGroup(status),
+		IssuedAt:     now.Unix(),
+		ExpiresAt:    now.Add(b.getSessionTTL()).Unix(),
+		IssuedAtNano: now.UnixNano(),
+	}
+	data, err := easyjson.Marshal(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + b.signSession(payload), claims, nil
+}
+
+func getUserGroup(status *userStatus) string {
+	if status.isWhitelist {
+		return sessionGroupWhitelist
+	}
+	return sessionGroupUser
+}
+
+func (b *BiliroamingGo) verifySession(token string) (*entity.SessionClaims, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(b.signSession(payload))) {
+		return nil, ErrSessionInvalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrSessionInvalid
+	}
+	claims := &entity.SessionClaims{}
+	if err := easyjson.Unmarshal(data, claims); err != nil {
+		return nil, ErrSessionInvalid
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrSessionExpired
+	}
+	if b.sessionRevocations.isRevoked(claims.UID, sessionIssuedAt(claims)) {
+		return nil, ErrSessionRevoked
+	}
+	return claims, nil
+}
+
+// sessionIssuedAt tokens issued by older versions have second precision only
+func sessionIssuedAt(claims *entity.SessionClaims) time.Time {
+	if claims.IssuedAtNano > 0 {
+		return time.Unix(0, claims.IssuedAtNano)
+	}
+	return time.Unix(claims.IssuedAt, 0)
+}
+
+// getSessionToken Authorization: Bearer <token>
+func (b *BiliroamingGo) getSessionToken(ctx *fasthttp.RequestCtx) string {
+	if b.sessionSecret == nil {
+		return ""
+	}
+	auth := ctx.Request.Header.Peek(fasthttp.HeaderAuthorization)
+	if !bytes.HasPrefix(auth, []byte("Bearer ")) {
+		return ""
+	}
+	return string(auth[len("Bearer "):])
+}
+
+func sessionCacheKey(uid int64) string {
+	return "session:" + strconv.FormatInt(uid, 10)
+}
+
+// getSessionBWlist current blacklist and whitelist status of token holder,
+// cached in auth cache and evicted with other keys of user, rejected when lookup fails
+func (b *BiliroamingGo) getSessionBWlist(ctx *fasthttp.RequestCtx, claims *entity.SessionClaims) (*userStatus, error) {
+	status := &userStatus{
+		isLogin:     true,
+		isVip:       claims.IsVip,
+		isWhitelist: claims.Group == sessionGroupWhitelist,
+		uid:         claims.UID,
+		keyless:     true,
+	}
+	if b.config.BlockType == BlockTypeDisabled {
+		return status, nil
+	}
+	cacheKey := sessionCacheKey(claims.UID)
+	if key, ok := b.getKey(cacheKey); ok {
+		status.isBlacklist = key.isBlacklist
+		status.isWhitelist = key.isWhitelist
+		status.banUntil = key.banUntil
+		return status, nil
+	}
+	// claims may be issued before ban, never trust them without a verdict
+	if err := b.fillBWlist(ctx, status); err != nil {
+		return nil, err
+	}
+	b.setKey(cacheKey, status)
+	return status, nil
+}
+
+// doSessionAuth same checks as doAuth with claims in token
+func (b *BiliroamingGo) doSessionAuth(ctx *fasthttp.RequestCtx, token string) (bool, *userStatus) {
+	claims, err := b.verifySession(token)
+	if err != nil {
+		b.sugar.Debug(err)
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_SESSION, MSG_ERROR_AUTH_SESSION)
+		return false, nil
+	}
+	if !b.doCheckUidLimiter(ctx, claims.UID) {
+		return false, nil
+	}
+	status, err := b.getSessionBWlist(ctx, claims)
+	if err != nil {
+		b.sugar.Error(err)
+		writeErrorJSON(ctx, ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+		return false, nil
+	}
+	switch b.config.BlockType {
+	case BlockTypeEnabled:
+		if status.isBlacklist && !b.reportOnly(policyBlacklist, fmt.Sprintf("uid %d", status.uid)) {
+			writeErrorJSON(ctx, ERROR_CODE_AUTH_BLACKLIST, fmt.Sprintf(MSG_ERROR_AUTH_BLACKLIST, status.uid, status.banUntil.In(LOCATION_SHANGHAI).Format(TIME_FORMAT)))
+			return false, nil
+		}
+	case BlockTypeWhitelist:
+		if !status.isWhitelist && !b.reportOnly(policyWhitelist, fmt.Sprintf("uid %d", status.uid)) {
+			writeErrorJSON(ctx, ERROR_CODE_AUTH_WHITELIST, MSG_ERROR_AUTH_WHITELIST)
+			return false, nil
+		}
+	}
+	return true, status
+}
+
+// revokeSessions revoke all issued session tokens of user on all instances
+func (b *BiliroamingGo) revokeSessions(uid int64) error {
+	// stored with microsecond precision, round up so tokens issued before are always covered
+	now := time.Now().Truncate(time.Microsecond).Add(time.Microsecond)
+	if err := b.db.RevokeSessions(uid, now); err != nil {
+		return err
+	}
+	b.sessionRevocations.set(uid, now)
+	b.publishInvalidation(&database.InvalidationEvent{
+		Type: database.InvalidationSessionRevoke,
+		UID:  uid,
+	})
+	return nil
+}
+
+func (b *BiliroamingGo) reloadSessionRevocation(uid int64) {
+	before, err := b.db.GetSessionRevocation(uid)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			b.sugar.Error(err)
+		}
+		return
+	}
+	b.sessionRevocations.set(uid, before)
+}
+
+func (b *BiliroamingGo) syncSessionRevocations() error {
+	revocations, err := b.db.GetSessionRevocations()
+	if err != nil {
+		return err
+	}
+	b.sessionRevocations.replace(revocations)
+	return nil
+}
+
+// handleSession exchange access_key for session token
+func (b *BiliroamingGo) handleSession(ctx *fasthttp.RequestCtx) {
+	queryArgs := ctx.URI().QueryArgs()
+	accessKey := string(queryArgs.Peek("access_key"))
+	if accessKey == "" {
+		writeErrorJSON(ctx, ERROR_CODE_AUTH_NOT_LOGIN, MSG_ERROR_AUTH_NOT_LOGIN)
+		return
+	}
+	clientType := b.getClientPlatform(ctx, string(queryArgs.Peek("appkey")))
+
+	ok, status := b.doAuth(ctx, accessKey, clientType, "", false)
+	if !ok {
+		return
+	}
+
+	token, claims, err := b.issueSession(status)
+	if err != nil {
+		b.processError(ctx, err)
+		return
+	}
+	writeJSON(ctx, &entity.SessionResponse{
+		Code:    0,
+		Message: "0",
+		Data: entity.SessionData{
+			Token:     token,
+			UID:       claims.UID,
+			IsVip:     claims.IsVip,
+			ExpiresAt: claims.ExpiresAt,
+		},
+	})
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+)
+
+func newTestSessionServer(t *testing.T, c *Config) *BiliroamingGo {
+	t.Helper()
+	b := newTestBiliroaming(t, c)
+	b.sessionSecret = []byte("test-secret")
+	return b
+}
+
+func signTestClaims(t *testing.T, b *BiliroamingGo, claims *entity.SessionClaims) string {
+	t.Helper()
+	data, err := easyjson.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + b.signSession(payload)
+}
+
+func TestSessionIssueVerify(t *testing.T) {
+	b := newTestSessionServer(t, nil)
+	token, issued, err := b.issueSession(&userStatus{uid: 1, isLogin: true, isVip: true, isWhitelist: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issued.ExpiresAt-issued.IssuedAt != int64(defaultSessionTTL/time.Second) {
+		t.Errorf("ttl = %ds, want %s", issued.ExpiresAt-issued.IssuedAt, defaultSessionTTL)
+	}
+
+	claims, err := b.verifySession(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UID != 1 || !claims.IsVip || claims.Group != sessionGroupWhitelist {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestSessionVerifyInvalid(t *testing.T) {
+	b := newTestSessionServer(t, nil)
+	token, _, err := b.issueSession(&userStatus{uid: 1, isLogin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged := signTestClaims(t, b, &entity.SessionClaims{UID: 2, IsVip: true, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	payload, _, _ := strings.Cut(forged, ".")
+	_, sig, _ := strings.Cut(token, ".")
+
+	other := newTestSessionServer(t, nil)
+	other.sessionSecret = []byte("other-secret")
+
+	for name, tc := range map[string]struct {
+		b     *BiliroamingGo
+		token string
+	}{
+		"tampered payload": {b, payload + "." + sig},
+		"other secret":     {other, token},
+		"no signature":     {b, payload},
+		"bad payload":      {b, "!!!." + b.signSession("!!!")},
+	} {
+		if _, err := tc.b.verifySession(tc.token); !errors.Is(err, ErrSessionInvalid) {
+			t.Errorf("%s: err = %v, want %v", name, err, ErrSessionInvalid)
+		}
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	b := newTestSessionServer(t, nil)
+	now := time.Now()
+	token := signTestClaims(t, b, &entity.SessionClaims{
+		UID:       1,
+		IssuedAt:  now.Add(-2 * time.Hour).Unix(),
+		ExpiresAt: now.Add(-time.Hour).Unix(),
+	})
+	if _, err := b.verifySession(token); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("err = %v, want %v", err, ErrSessionExpired)
+	}
+}
+
+func TestSessionRevoked(t *testing.T) {
+	b := newTestSessionServer(t, nil)
+	now := time.Now()
+	before := signTestClaims(t, b, &entity.SessionClaims{UID: 1, IssuedAt: now.Add(-time.Minute).Unix(), ExpiresAt: now.Add(time.Hour).Unix()})
+	after := signTestClaims(t, b, &entity.SessionClaims{UID: 1, IssuedAt: now.Add(time.Minute).Unix(), ExpiresAt: now.Add(time.Hour).Unix()})
+	otherUser := signTestClaims(t, b, &entity.SessionClaims{UID: 2, IssuedAt: now.Add(-time.Minute).Unix(), ExpiresAt: now.Add(time.Hour).Unix()})
+
+	b.sessionRevocations.set(1, now)
+
+	if _, err := b.verifySession(before); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("issued before revocation: err = %v, want %v", err, ErrSessionRevoked)
+	}
+	if _, err := b.verifySession(after); err != nil {
+		t.Errorf("issued after revocation: err = %v", err)
+	}
+	if _, err := b.verifySession(otherUser); err != nil {
+		t.Errorf("other user: err = %v", err)
+	}
+
+	// revocation is never moved backward
+	b.sessionRevocations.set(1, now.Add(-time.Hour))
+	if _, err := b.verifySession(before); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("after older revocation: err = %v, want %v", err, ErrSessionRevoked)
+	}
+}
+
+func TestSessionAuthBanned(t *testing.T) {
+	b := newTestSessionServer(t, &Config{BlockType: BlockTypeEnabled})
+	token, _, err := b.issueSession(&userStatus{uid: 1, isLogin: true, isVip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.setKey(sessionCacheKey(1), &userStatus{uid: 1, isLogin: true})
+	ctx := &fasthttp.RequestCtx{}
+	if ok, status := b.doSessionAuth(ctx, token); !ok || status.uid != 1 || !status.isVip {
+		t.Fatalf("not banned: ok = %t, status = %+v", ok, status)
+	}
+
+	// banned after token was issued
+	b.authCache.deleteByUID(1)
+	b.setKey(sessionCacheKey(1), &userStatus{uid: 1, isLogin: true, isBlacklist: true, banUntil: time.Now().Add(time.Hour)})
+	ctx = &fasthttp.RequestCtx{}
+	if ok, _ := b.doSessionAuth(ctx, token); ok {
+		t.Fatal("banned user accepted")
+	}
+	resp := &entity.SimpleResponse{}
+	if err := easyjson.Unmarshal(ctx.Response.Body(), resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != ERROR_CODE_AUTH_BLACKLIST {
+		t.Errorf("code = %d, want %d", resp.Code, ERROR_CODE_AUTH_BLACKLIST)
+	}
+}
+
+func TestSessionAuthWhitelistRemoved(t *testing.T) {
+	b := newTestSessionServer(t, &Config{BlockType: BlockTypeWhitelist})
+	token, _, err := b.issueSession(&userStatus{uid: 1, isLogin: true, isWhitelist: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.setKey(sessionCacheKey(1), &userStatus{uid: 1, isLogin: true})
+	if ok, _ := b.doSessionAuth(&fasthttp.RequestCtx{}, token); ok {
+		t.Fatal("user removed from whitelist accepted")
+	}
+}
+
+func TestSessionRevokedSameSecond(t *testing.T) {
+	b := newTestSessionServer(t, nil)
+	revokedAt := time.Unix(time.Now().Unix(), int64(500*time.Millisecond))
+	b.sessionRevocations.set(1, revokedAt)
+
+	before := signTestClaims(t, b, &entity.SessionClaims{UID: 1, IssuedAt: revokedAt.Unix(), IssuedAtNano: revokedAt.Add(-time.Millisecond).UnixNano(), ExpiresAt: revokedAt.Add(time.Hour).Unix()})
+	after := signTestClaims(t, b, &entity.SessionClaims{UID: 1, IssuedAt: revokedAt.Unix(), IssuedAtNano: revokedAt.Add(time.Millisecond).UnixNano(), ExpiresAt: revokedAt.Add(time.Hour).Unix()})
+	if _, err := b.verifySession(before); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("issued before revocation: err = %v, want %v", err, ErrSessionRevoked)
+	}
+	if _, err := b.verifySession(after); err != nil {
+		t.Errorf("issued after revocation in same second: err = %v", err)
+	}
+}
+
+func TestSessionAuthLookupFailed(t *testing.T) {
+	b := newTestSessionServer(t, &Config{BlockType: BlockTypeEnabled, BlacklistApiUrl: "http://blacklist.test/%d"})
+	b.defaultClient = &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) {
+			return nil, errors.New("unavailable")
+		},
+	}
+	token, _, err := b.issueSession(&userStatus{uid: 1, isLogin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := b.doSessionAuth(&fasthttp.RequestCtx{}, token); ok {
+		t.Fatal("accepted without blacklist verdict")
+	}
+}
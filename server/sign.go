@@ -0,0 +1,137 @@
+package server
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultSignSkew  = time.Minute
+	defaultMaxNonces = 100000
+)
+
+// nonceBackend used signs storage for replay protection
+type nonceBackend interface {
+	add(key string, expiresAt time.Time) (bool, error)
+	remove(key string) error
+	cleanup() int64
+}
+
+// memoryNonceStore in-memory nonces for single node, bounded by maxEntries,
+// nonces are kept for twice the skew which covers signs dated in the future
+type memoryNonceStore struct {
+	nonces *shardedCache[time.Time]
+}
+
+func newMemoryNonceStore(maxEntries int, skew time.Duration, m *metrics) *memoryNonceStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxNonces
+	}
+	return &memoryNonceStore{
+		nonces: newShardedCache[time.Time]("nonce", maxEntries, 2*skew, false, m),
+	}
+}
+
+func (s *memoryNonceStore) add(key string, expiresAt time.Time) (bool, error) {
+	fresh := false
+	s.nonces.getOrCreate(key, func() time.Time {
+		fresh = true
+		return expiresAt
+	})
+	return fresh, nil
+}
+
+func (s *memoryNonceStore) remove(key string) error {
+	s.nonces.delete(key)
+	return nil
+}
+
+func (s *memoryNonceStore) cleanup() int64 {
+	return s.nonces.cleanup()
+}
+
+// pgNonceStore nonces shared across replicas by postgres
+type pgNonceStore struct {
+	b *BiliroamingGo
+}
+
+func (s *pgNonceStore) add(key string, expiresAt time.Time) (bool, error) {
+	return s.b.db.InsertNonce(key, expiresAt)
+}
+
+func (s *pgNonceStore) remove(key string) error {
+	return s.b.db.DeleteNonce(key)
+}
+
+func (s *pgNonceStore) cleanup() int64 {
+	// shared tables are cleaned by leader, see cleanupSharedState job
+	return 0
+}
+
+func (b *BiliroamingGo) getSignSkew() time.Duration {
+	if b.config.SignCheck.Skew > 0 {
+		return b.config.SignCheck.Skew
+	}
+	return defaultSignSkew
+}
+
+func (b *BiliroamingGo) rejectSign(ctx *fasthttp.RequestCtx, reason string, msg string) bool {
+	b.metrics.inc("biliroaming_sign_rejections_total", "reason", reason)
+	writeErrorJSON(ctx, ERROR_CODE_PARAMETERS, msg)
+	return false
+}
+
+// verifySign check timestamp skew, sign and replay of signed request,
+// expect limit appkey to client type, empty for any registered appkey,
+// return the recorded nonce, empty if request is not signed
+func (b *BiliroamingGo) verifySign(ctx *fasthttp.RequestCtx, args *biliArgs, expect ClientType, required bool) (string, bool) {
+	if args.appkey == "" && args.sign == "" && args.ts == 0 {
+		if required {
+			return "", b.rejectSign(ctx, "missing", MSG_ERROR_PARAMETERS)
+		}
+		return "", true
+	}
+
+	skew := b.getSignSkew()
+	signedAt := time.Unix(args.ts, 0)
+	if d := time.Since(signedAt); d > skew || d < -skew {
+		return "", b.rejectSign(ctx, "skew", MSG_ERROR_PARAMETERS)
+	}
+
+	entry, ok := b.appKeys.lookup(args.appkey)
+	if !ok || (expect != "" && entry.ClientType != expect) {
+		return "", b.rejectSign(ctx, "appkey", MSG_ERROR_PARAMETERS)
+	}
+
+	values, err := url.ParseQuery(ctx.URI().QueryArgs().String())
+	if err != nil {
+		return "", b.rejectSign(ctx, "query", MSG_ERROR_PARAMETERS)
+	}
+	values.Del("sign")
+	if signWith(values, entry, args.ts) != args.sign {
+		return "", b.rejectSign(ctx, "sign", MSG_ERROR_PARAMETERS)
+	}
+
+	// recorded before serving so concurrent replays are rejected,
+	// released by signMiddleware if the request fails
+	nonce := args.appkey + ":" + args.sign
+	fresh, err := b.nonces.add(nonce, signedAt.Add(skew))
+	if err != nil {
+		// fail open when nonce backend is unavailable
+		b.sugar.Error(err)
+		return "", true
+	}
+	if !fresh {
+		return "", b.rejectSign(ctx, "replay", MSG_ERROR_SIGN_REPLAYED)
+	}
+	return nonce, true
+}
+
+// releaseNonce allow sign of failed request to be used again
+func (b *BiliroamingGo) releaseNonce(nonce string) {
+	if err := b.nonces.remove(nonce); err != nil {
+		b.sugar.Error(err)
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// newSignedCtx request signed by first appkey of client type
+func newSignedCtx(t *testing.T, b *BiliroamingGo, clientType ClientType, signedAt time.Time) *fasthttp.RequestCtx {
+	t.Helper()
+	entry, ok := b.appKeys.get(clientType)
+	if !ok {
+		t.Fatalf("no appkey of %s", clientType)
+	}
+	v := url.Values{}
+	v.Set("area", "hk")
+	v.Set("ep_id", "1")
+	v.Set("sign", signWith(v, entry, signedAt.Unix()))
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/pgc/player/api/playurl?" + v.Encode())
+	return ctx
+}
+
+func verifyTestSign(b *BiliroamingGo, ctx *fasthttp.RequestCtx, expect ClientType) bool {
+	_, ok := b.verifySign(ctx, b.getArgs(ctx), expect, true)
+	return ok
+}
+
+func TestVerifySignSkew(t *testing.T) {
+	b := newTestBiliroaming(t, nil)
+	for _, tc := range []struct {
+		name   string
+		offset time.Duration
+		ok     bool
+	}{
+		{"now", 0, true},
+		{"past within skew", -defaultSignSkew / 2, true},
+		{"future within skew", defaultSignSkew / 2, true},
+		{"past beyond skew", -2 * defaultSignSkew, false},
+		{"future beyond skew", 2 * defaultSignSkew, false},
+	} {
+		ctx := newSignedCtx(t, b, ClientTypeAndroid, time.Now().Add(tc.offset))
+		if ok := verifyTestSign(b, ctx, ""); ok != tc.ok {
+			t.Errorf("%s: ok = %v, want %v", tc.name, ok, tc.ok)
+		}
+	}
+}
+
+func TestVerifySignReplay(t *testing.T) {
+	b := newTestBiliroaming(t, nil)
+	signedAt := time.Now()
+	if !verifyTestSign(b, newSignedCtx(t, b, ClientTypeAndroid, signedAt), "") {
+		t.Fatal("first request rejected")
+	}
+	if verifyTestSign(b, newSignedCtx(t, b, ClientTypeAndroid, signedAt), "") {
+		t.Error("replayed request accepted")
+	}
+}
+
+func TestVerifySignWrongAppkey(t *testing.T) {
+	b := newTestBiliroaming(t, nil)
+	ctx := newSignedCtx(t, b, ClientTypeAndroid, time.Now())
+	if verifyTestSign(b, ctx, ClientTypeBstarA) {
+		t.Error("android appkey accepted for bstar_a")
+	}
+	ctx = newSignedCtx(t, b, ClientTypeBstarA, time.Now())
+	if !verifyTestSign(b, ctx, ClientTypeBstarA) {
+		t.Error("bstar_a appkey rejected")
+	}
+}
+
+func TestSignMiddlewareReleaseFailed(t *testing.T) {
+	b := newTestBiliroaming(t, nil)
+	fail := true
+	handler := b.signMiddleware("", true)(func(ctx *fasthttp.RequestCtx) {
+		if fail {
+			writeErrorJSON(ctx, ERROR_CODE_INTERNAL_SERVER, MSG_ERROR_INTERNAL_SERVER)
+		}
+	})
+	signedAt := time.Now()
+	served := func() bool {
+		ctx := newSignedCtx(t, b, ClientTypeAndroid, signedAt)
+		handler(ctx)
+		failed, _ := ctx.UserValue(userValueFailed).(bool)
+		return !failed
+	}
+
+	served()
+	fail = false
+	if !served() {
+		t.Fatal("retry of failed request rejected")
+	}
+	if served() {
+		t.Error("replay of served request accepted")
+	}
+}
+
+func TestSignMiddlewareReleasePanicked(t *testing.T) {
+	b := newTestBiliroaming(t, nil)
+	panicked := true
+	handler := b.recoverMiddleware(b.signMiddleware("", true)(func(ctx *fasthttp.RequestCtx) {
+		if panicked {
+			panic("handler")
+		}
+	}))
+	signedAt := time.Now()
+	served := func() bool {
+		ctx := newSignedCtx(t, b, ClientTypeAndroid, signedAt)
+		handler(ctx)
+		failed, _ := ctx.UserValue(userValueFailed).(bool)
+		return !failed
+	}
+
+	if served() {
+		t.Fatal("panicked request served")
+	}
+	panicked = false
+	if !served() {
+		t.Error("retry of panicked request rejected")
+	}
+}
+
+func TestMemoryNonceStoreBounded(t *testing.T) {
+	s := newMemoryNonceStore(cacheShardCount, time.Minute, nil)
+	expiresAt := time.Now().Add(time.Minute)
+	for i := 0; i < 10*cacheShardCount; i++ {
+		if fresh, _ := s.add(strconv.Itoa(i), expiresAt); !fresh {
+			t.Fatalf("nonce %d not fresh", i)
+		}
+	}
+	var total int
+	for _, shard := range s.nonces.shards {
+		total += len(shard.items)
+	}
+	if total > cacheShardCount {
+		t.Errorf("%d nonces kept, max %d", total, cacheShardCount)
+	}
+}
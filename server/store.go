@@ -0,0 +1,356 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"golang.org/x/time/rate"
+)
+
+// SharedStateBackend 限制器与鉴权缓存的存储后端
+type SharedStateBackend string
+
+// SharedStateBackend
+const (
+	SharedStateMemory   SharedStateBackend = "memory"
+	SharedStatePostgres SharedStateBackend = "postgres"
+)
+
+const (
+	authCacheDuration          = 15 * time.Minute
+	defaultAuthCacheMaxEntries = 100000
+	defaultAuthCacheLocalTTL   = 10 * time.Second
+)
+
+// limiterBackend limiter state storage
+type limiterBackend interface {
+	allow(key string) (*limitResult, error)
+	// peek report state without taking token
+	peek(key string) (*limitResult, error)
+	cleanup() int64
+}
+
+// authBackend auth cache storage
+type authBackend interface {
+	get(key string) (*accessKey, bool)
+	set(key string, value *accessKey)
+	delete(key string)
+	deleteByUID(uid int64)
+	flush()
+	cleanup(duration time.Duration) int64
+}
+
+// AuthEntry cached auth result of access key or cookie
+type AuthEntry struct {
+	UID         int64
+	IsLogin     bool
+	IsVip       bool
+	IsBlacklist bool
+	IsWhitelist bool
+	BanUntil    time.Time
+	Timestamp   time.Time
+}
+
+// AuthStore custom auth cache storage, set by WithAuthStore
+type AuthStore interface {
+	Get(key string) (*AuthEntry, bool)
+	Set(key string, value *AuthEntry)
+	Delete(key string)
+	DeleteByUID(uid int64)
+	Flush()
+	// Cleanup remove entries older than duration, return removed count
+	Cleanup(duration time.Duration) int64
+}
+
+// externalAuthStore adapt AuthStore to authBackend
+type externalAuthStore struct {
+	store AuthStore
+}
+
+func (s *externalAuthStore) get(key string) (*accessKey, bool) {
+	entry, ok := s.store.Get(key)
+	if !ok || entry == nil {
+		return nil, false
+	}
+	return &accessKey{
+		uid:         entry.UID,
+		isLogin:     entry.IsLogin,
+		isVip:       entry.IsVip,
+		isBlacklist: entry.IsBlacklist,
+		isWhitelist: entry.IsWhitelist,
+		banUntil:    entry.BanUntil,
+		timestamp:   entry.Timestamp,
+	}, true
+}
+
+func (s *externalAuthStore) set(key string, value *accessKey) {
+	s.store.Set(key, &AuthEntry{
+		UID:         value.uid,
+		IsLogin:     value.isLogin,
+		IsVip:       value.isVip,
+		IsBlacklist: value.isBlacklist,
+		IsWhitelist: value.isWhitelist,
+		BanUntil:    value.banUntil,
+		Timestamp:   value.timestamp,
+	})
+}
+
+func (s *externalAuthStore) delete(key string) {
+	s.store.Delete(key)
+}
+
+func (s *externalAuthStore) deleteByUID(uid int64) {
+	s.store.DeleteByUID(uid)
+}
+
+func (s *externalAuthStore) flush() {
+	s.store.Flush()
+}
+
+func (s *externalAuthStore) cleanup(duration time.Duration) int64 {
+	return s.store.Cleanup(duration)
+}
+
+func (s *visitorStore) allow(key string) (*limitResult, error) {
+	return allowLimiter(s.get(key)), nil
+}
+
+func (s *visitorStore) peek(key string) (*limitResult, error) {
+	limiter, ok := s.visitors.get(key)
+	if !ok {
+		// not created yet, bucket is full
+		limiter = rate.NewLimiter(s.limit, s.burst)
+	}
+	return peekLimiter(limiter), nil
+}
+
+// memoryAuthStore in-memory auth cache for single node
+type memoryAuthStore struct {
+	keys *shardedCache[*accessKey]
+}
+
+func newMemoryAuthStore(maxEntries int, m *metrics) *memoryAuthStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultAuthCacheMaxEntries
+	}
+	return &memoryAuthStore{
+		keys: newShardedCache[*accessKey]("auth", maxEntries, authCacheDuration, false, m),
+	}
+}
+
+func (s *memoryAuthStore) get(key string) (*accessKey, bool) {
+	return s.keys.get(key)
+}
+
+func (s *memoryAuthStore) set(key string, value *accessKey) {
+	s.keys.set(key, value)
+}
+
+func (s *memoryAuthStore) delete(key string) {
+	s.keys.delete(key)
+}
+
+func (s *memoryAuthStore) deleteByUID(uid int64) {
+	s.keys.deleteFunc(func(v *accessKey) bool {
+		return v.uid == uid
+	})
+}
+
+func (s *memoryAuthStore) flush() {
+	s.keys.flush()
+}
+
+func (s *memoryAuthStore) cleanup(duration time.Duration) int64 {
+	return s.keys.cleanup()
+}
+
+// pgLimiterStore token buckets shared across replicas by postgres
+type pgLimiterStore struct {
+	b      *BiliroamingGo
+	prefix string
+	limit  rate.Limit
+	burst  int
+}
+
+func (s *pgLimiterStore) allow(key string) (*limitResult, error) {
+	result := &limitResult{
+		allowed: true,
+		limit:   s.burst,
+	}
+	if s.limit == rate.Inf {
+		result.remaining = s.burst
+		return result, nil
+	}
+
+	allowed, tokens, err := s.b.db.AllowRateLimit(s.prefix+key, float64(s.limit), s.burst)
+	if err != nil {
+		return result, err
+	}
+	result.allowed = allowed
+	if tokens > 0 {
+		result.remaining = int(math.Floor(tokens))
+	}
+	if s.limit > 0 {
+		if missing := float64(s.burst) - tokens; missing > 0 {
+			result.reset = time.Duration(missing / float64(s.limit) * float64(time.Second))
+		}
+		if !allowed {
+			result.retryAfter = time.Duration((1 - tokens) / float64(s.limit) * float64(time.Second))
+		}
+	}
+	return result, nil
+}
+
+func (s *pgLimiterStore) peek(key string) (*limitResult, error) {
+	result := &limitResult{
+		allowed:   true,
+		limit:     s.burst,
+		remaining: s.burst,
+	}
+	if s.limit == rate.Inf {
+		return result, nil
+	}
+
+	tokens, err := s.b.db.PeekRateLimit(s.prefix+key, float64(s.limit), s.burst)
+	if err != nil {
+		return result, err
+	}
+	if tokens < 1 {
+		result.allowed = false
+		if s.limit > 0 {
+			result.retryAfter = time.Duration((1 - tokens) / float64(s.limit) * float64(time.Second))
+		}
+	}
+	fillLimitResult(result, s.limit, tokens)
+	return result, nil
+}
+
+func (s *pgLimiterStore) cleanup() int64 {
+	// shared tables are cleaned by leader, see cleanupSharedState job
+	return 0
+}
+
+// pgAuthStore auth cache shared across replicas by postgres,
+// short local cache in front, evicted by invalidation events
+type pgAuthStore struct {
+	b     *BiliroamingGo
+	local *shardedCache[*accessKey]
+}
+
+func newPgAuthStore(b *BiliroamingGo, maxEntries int, ttl time.Duration) *pgAuthStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultAuthCacheMaxEntries
+	}
+	if ttl <= 0 {
+		ttl = defaultAuthCacheLocalTTL
+	}
+	return &pgAuthStore{
+		b:     b,
+		local: newShardedCache[*accessKey]("authLocal", maxEntries, ttl, false, b.metrics),
+	}
+}
+
+func (s *pgAuthStore) get(key string) (*accessKey, bool) {
+	if v, ok := s.local.get(key); ok && time.Since(v.timestamp) <= authCacheDuration {
+		return v, true
+	}
+	c, err := s.b.db.GetAuthCache(key)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.b.sugar.Error(err)
+		}
+		return nil, false
+	}
+	if time.Since(c.UpdatedAt) > authCacheDuration {
+		return nil, false
+	}
+	v := &accessKey{
+		uid:         c.UID,
+		isLogin:     c.IsLogin,
+		isVip:       c.IsVip,
+		isBlacklist: c.IsBlacklist,
+		isWhitelist: c.IsWhitelist,
+		banUntil:    c.BanUntil,
+		timestamp:   c.UpdatedAt,
+	}
+	s.local.set(key, v)
+	return v, true
+}
+
+func (s *pgAuthStore) set(key string, value *accessKey) {
+	s.local.set(key, value)
+	if err := s.b.db.InsertOrUpdateAuthCache(key, &database.AuthCache{
+		UID:         value.uid,
+		IsLogin:     value.isLogin,
+		IsVip:       value.isVip,
+		IsBlacklist: value.isBlacklist,
+		IsWhitelist: value.isWhitelist,
+		BanUntil:    value.banUntil,
+		UpdatedAt:   value.timestamp,
+	}); err != nil {
+		s.b.sugar.Error(err)
+	}
+}
+
+func (s *pgAuthStore) delete(key string) {
+	s.local.delete(key)
+	if err := s.b.db.DeleteAuthCache(key); err != nil {
+		s.b.sugar.Error(err)
+	}
+}
+
+func (s *pgAuthStore) deleteByUID(uid int64) {
+	s.local.deleteFunc(func(v *accessKey) bool {
+		return v.uid == uid
+	})
+	if err := s.b.db.DeleteAuthCacheByUID(uid); err != nil {
+		s.b.sugar.Error(err)
+	}
+}
+
+// flush only local cache, shared table is kept for other instances
+func (s *pgAuthStore) flush() {
+	s.local.flush()
+}
+
+func (s *pgAuthStore) cleanup(duration time.Duration) int64 {
+	// shared tables are cleaned by leader, see cleanupSharedState job
+	return s.local.cleanup()
+}
+
+func (b *BiliroamingGo) newLimiterBackend(prefix string, limit int, burst int, maxEntries int) limiterBackend {
+	if b.config.SharedState.Backend == SharedStatePostgres {
+		rt := rate.Inf
+		if limit > 0 {
+			rt = rate.Every(time.Second / time.Duration(limit))
+		}
+		return &pgLimiterStore{
+			b:      b,
+			prefix: prefix + ":",
+			limit:  rt,
+			burst:  burst,
+		}
+	}
+	return newVisitorStore(prefix, limit, burst, maxEntries, b.metrics)
+}
+
+// initSharedState init limiter and auth cache backend, memory by default
+func (b *BiliroamingGo) initSharedState(c *Config) error {
+	switch c.SharedState.Backend {
+	case "", SharedStateMemory:
+		b.authCache = newMemoryAuthStore(c.Cache.AccessKeyMaxEntries, b.metrics)
+		b.nonces = newMemoryNonceStore(c.SignCheck.MaxNonces, b.getSignSkew(), b.metrics)
+	case SharedStatePostgres:
+		b.authCache = newPgAuthStore(b, c.Cache.AccessKeyMaxEntries, c.SharedState.LocalTTL)
+		b.nonces = &pgNonceStore{b: b}
+	default:
+		return errors.New("unknown shared state backend " + string(c.SharedState.Backend))
+	}
+	b.uidLimiter = b.newLimiterBackend("uid", c.Limiter.Limit, c.Limiter.Burst, c.Limiter.MaxEntries)
+	b.searchLimiter = b.newLimiterBackend("search", c.SearchLimiter.Limit, c.SearchLimiter.Burst, c.SearchLimiter.MaxEntries)
+	b.ipLimiter = b.newLimiterBackend("ip", c.IpLimiter.Limit, c.IpLimiter.Burst, c.IpLimiter.MaxEntries)
+	return nil
+}
@@ -1,4 +1,4 @@
-package main
+package server
 
 import (
 	"errors"
@@ -12,14 +12,7 @@ import (
 )
 
 func (b *BiliroamingGo) handleBstarAndroidSubtitle(ctx *fasthttp.RequestCtx) {
-	if !b.checkRoamingVer(ctx) {
-		return
-	}
-
-	queryArgs := ctx.URI().QueryArgs()
-	args := b.processArgs(queryArgs)
-
-	args.area = "th"
+	args := b.getArgs(ctx)
 
 	client := b.getClientByArea(args.area)
 
@@ -40,9 +33,9 @@ func (b *BiliroamingGo) handleBstarAndroidSubtitle(ctx *fasthttp.RequestCtx) {
 	v.Set("access_key", args.accessKey)
 	v.Set("s_locale", "zh_SG")
 	v.Set("ep_id", strconv.FormatInt(args.epId, 10))
-	v.Set("mobi_app", "bstar_a")
+	b.appKeys.setMobiApp(v, ClientTypeBstarA)
 
-	params, err := SignParams(v, ClientTypeBstarA)
+	params, err := b.SignParams(v, ClientTypeBstarA)
 	if err != nil {
 		b.processError(ctx, err)
 		return
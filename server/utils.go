@@ -0,0 +1,251 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/entity"
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+)
+
+var reMid = regexp.MustCompile(`(&|\\u0026)mid=\d+`)
+
+// ClientType ...
+type ClientType string
+
+// ClientType
+const (
+	ClientTypeUnknown            ClientType = "unknown"
+	ClientTypeAi4cCreatorAndroid ClientType = "ai4c_creator_android"
+	ClientTypeAndroid            ClientType = "android"
+	ClientTypeAndroidB           ClientType = "android_b"
+	ClientTypeAndroidBiliThings  ClientType = "android_bilithings"
+	ClientTypeAndroidHD          ClientType = "android_hd"
+	ClientTypeAndroidI           ClientType = "android_i"
+	ClientTypeAndroidMallTicket  ClientType = "android_mall_ticket"
+	ClientTypeAndroidOttSdk      ClientType = "android_ott_sdk"
+	ClientTypeAndroidTV          ClientType = "android_tv"
+	ClientTypeAnguAndroid        ClientType = "angu_android"
+	ClientTypeBiliLink           ClientType = "biliLink"
+	ClientTypeBiliScan           ClientType = "biliScan"
+	ClientTypeBstarA             ClientType = "bstar_a"
+	ClientTypeWeb                ClientType = "web"
+	ClientTypeIphone             ClientType = "iphone"
+)
+
+func (c ClientType) String() string {
+	return string(c)
+}
+
+// biliArgs query arguments struct
+type biliArgs struct {
+	accessKey      string
+	area           string
+	cid            int64
+	epId           int64
+	seasonId       int64
+	keyword        string
+	pn             int
+	page           int
+	qn             int
+	aType          int
+	fnval          int
+	appkey         string
+	ts             int64
+	sign           string
+	preferCodeType bool
+}
+
+func getAreaCode(area string) database.Area {
+	switch strings.ToLower(area) {
+	case "cn":
+		return database.AreaCN
+	case "hk":
+		return database.AreaHK
+	case "tw":
+		return database.AreaTW
+	case "th":
+		return database.AreaTH
+	default:
+		return database.AreaNone
+	}
+}
+
+// getFormatType ...
+//
+//	[   0] - FLV (或许是 自动)
+//	[   1] - MP4
+//	[   2] - ? (可能是 FLV)
+//	[   4] - ?
+//	[   8] - ?
+//	[  16] - DASH
+//	[  32] - ?
+//	[  64] - [DASH |QN 125] HDR
+//	[ 128] - [FOURK|QN 120] 4K
+//	[ 256] - [DASH |      ] DOLBY AUDIO
+//	[ 512] - [DASH |      ] DOLBY VISION
+//	[1024] - [DASH |QN 127] 8K
+//	[2048] - [DASH |      ] AV1
+//
+//	FLV     0
+//	MP4     1
+//	FLV     2
+//	DASH 4048
+func getFormatType(fnval int) database.FormatType {
+	if fnval&1 == 1 {
+		return database.FormatTypeMp4
+	} else if fnval&2 == 2 {
+		return database.FormatTypeFlv
+	} else if fnval == 0 || fnval&16 == 16 {
+		return database.FormatTypeDash
+	} else {
+		return database.FormatTypeUnknown
+	}
+}
+
+func isResponseLimited(data []byte) (bool, error) {
+	resp := &entity.SimpleResponse{}
+	if err := easyjson.Unmarshal(data, resp); err != nil {
+		return false, err
+	}
+	if resp.Code == -412 {
+		return true, nil
+	}
+	return false, nil
+}
+
+func isAvailableResponse(data []byte) (bool, error) {
+	resp := &entity.SimpleResponse{}
+	if err := easyjson.Unmarshal(data, resp); err != nil {
+		return false, err
+	}
+
+	/*
+		{"code":10015002,"message":"访问权限不足","ttl":1}
+		{"code":-10403,"message":"大会员专享限制"}
+		{"code":-10403,"message":"抱歉您所使用的平台不可观看！"}
+		{"code":-10403,"message":"抱歉您所在地区不可观看！"}
+		{"code":-400,"message":"请求错误"}
+		{"code":-404,"message":"啥都木有"}
+		{"code":-404,"message":"啥都木有","ttl":1}
+	*/
+
+	if resp.Code == 0 {
+		return true, nil
+	}
+	if resp.Code == 10015002 && resp.Message == "访问权限不足" {
+		return true, nil
+	}
+	if resp.Code == -10403 && resp.Message == "大会员专享限制" {
+		return true, nil
+	}
+	if resp.Code == -10403 && resp.Message == "抱歉您所使用的平台不可观看！" {
+		return true, nil
+	}
+	if resp.Code == -10403 && resp.Message == "抱歉您所在地区不可观看！" {
+		return false, nil
+	}
+	if resp.Code == -404 && resp.Message == "啥都木有" {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("code: %d, message: %s", resp.Code, resp.Message)
+}
+
+func isResponseNotLogin(data []byte) (bool, error) {
+	resp := &entity.SimpleResponse{}
+	if err := easyjson.Unmarshal(data, resp); err != nil {
+		return false, err
+	}
+	if resp.Code == -101 {
+		return true, nil
+	}
+	return false, nil
+}
+
+func removeMid(data string) string {
+	s := reMid.FindAllString(data, 1)
+	if len(s) > 0 {
+		data = strings.ReplaceAll(data, s[0], "")
+	}
+	return data
+}
+
+func (b *BiliroamingGo) processArgs(ctx *fasthttp.RequestCtx, args *fasthttp.Args) *biliArgs {
+	area := string(args.Peek("area"))
+	if area == "" {
+		area = b.getDefaultArea(ctx)
+	}
+	cid, err := strconv.ParseInt(string(args.Peek("cid")), 10, 64)
+	if err != nil {
+		cid = 0
+	}
+	epId, err := strconv.ParseInt(string(args.Peek("ep_id")), 10, 64)
+	if err != nil {
+		epId = 0
+	}
+	seasonId, err := strconv.ParseInt(string(args.Peek("season_id")), 10, 64)
+	if err != nil {
+		seasonId = 0
+	}
+	pn, err := strconv.Atoi(string(args.Peek("pn")))
+	if err != nil {
+		pn = 0
+	}
+	page, err := strconv.Atoi(string(args.Peek("page")))
+	if err != nil {
+		page = 0
+	}
+	qn, err := strconv.Atoi(string(args.Peek("qn")))
+	if err != nil {
+		qn = 0
+	}
+	aType, err := strconv.Atoi(string(args.Peek("type")))
+	if err != nil {
+		aType = 0
+	}
+	fnval, err := strconv.Atoi(string(args.Peek("fnval")))
+	if err != nil {
+		fnval = 0
+	}
+	ts, err := strconv.ParseInt(string(args.Peek("ts")), 10, 64)
+	if err != nil {
+		ts = 0
+	}
+	preferCodeType := bytes.EqualFold(args.Peek("prefer_code_type"), []byte("1"))
+
+	accessKey := string(args.Peek("access_key"))
+	if len(accessKey) > 32 {
+		accessKey = accessKey[:32]
+	}
+
+	queryArgs := &biliArgs{
+		accessKey:      accessKey,
+		area:           strings.ToLower(area),
+		cid:            cid,
+		epId:           epId,
+		seasonId:       seasonId,
+		keyword:        string(args.Peek("keyword")),
+		pn:             pn,
+		page:           page,
+		qn:             qn,
+		aType:          aType,
+		fnval:          fnval,
+		appkey:         string(args.Peek("appkey")),
+		ts:             ts,
+		sign:           string(args.Peek("sign")),
+		preferCodeType: preferCodeType,
+	}
+
+	b.sugar.Debug("Request args ", args.String())
+	b.sugar.Debugf(
+		"Parsed request args: %v",
+		queryArgs,
+	)
+	return queryArgs
+}
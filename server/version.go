@@ -0,0 +1,88 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/valyala/fasthttp"
+)
+
+// VersionPolicy 客户端版本策略
+type VersionPolicy struct {
+	MinBuild       map[ClientType]int `yaml:"minBuild"`
+	BlockedBuilds  []BuildRange       `yaml:"blockedBuilds"`
+	UpgradeMessage string             `yaml:"upgradeMessage"`
+	DownloadUrl    string             `yaml:"downloadUrl"`
+	RequireHeader  bool               `yaml:"requireHeader"`
+}
+
+// BuildRange inclusive build range
+type BuildRange struct {
+	From int `yaml:"from"`
+	To   int `yaml:"to"`
+}
+
+func (r BuildRange) contains(build int) bool {
+	return build >= r.From && build <= r.To
+}
+
+// getMinBuild per client type minimum, fallback to RoamingMinVer
+func (b *BiliroamingGo) getMinBuild(clientType ClientType) int {
+	if minBuild, ok := b.config.VersionPolicy.MinBuild[clientType]; ok {
+		return minBuild
+	}
+	return b.config.RoamingMinVer
+}
+
+// getUpgradeMessage append custom message and download url
+func (b *BiliroamingGo) getUpgradeMessage(msg string) string {
+	p := b.config.VersionPolicy
+	if p.UpgradeMessage != "" {
+		msg = p.UpgradeMessage
+	}
+	if p.DownloadUrl != "" {
+		msg = fmt.Sprintf("%s\n%s", msg, p.DownloadUrl)
+	}
+	return msg
+}
+
+func (b *BiliroamingGo) checkRoamingVer(ctx *fasthttp.RequestCtx) bool {
+	return b.checkVersionPolicy(ctx, b.config.VersionPolicy.RequireHeader)
+}
+
+// checkVersionPolicy requireHeader reject requests not from biliroaming
+func (b *BiliroamingGo) checkVersionPolicy(ctx *fasthttp.RequestCtx, requireHeader bool) bool {
+	versionCode := ctx.Request.Header.PeekBytes([]byte("build"))
+	versionName := ctx.Request.Header.PeekBytes([]byte("x-from-biliroaming"))
+
+	if len(versionCode) == 0 && len(versionName) == 0 {
+		if requireHeader && !b.reportOnly(policyVersion, "missing header from "+getClientIP(ctx).String()) {
+			writeErrorJSON(ctx, ERROR_CODE_HEADER_MISSING, MSG_ERROR_HEADER_MISSING)
+			return false
+		}
+		return true
+	}
+
+	if len(versionCode) > 0 && len(versionName) > 0 {
+		build, err := strconv.Atoi(string(versionCode))
+		if err != nil {
+			writeErrorJSON(ctx, ERROR_CODE_HEADER_WRONG, MSG_ERROR_HEADER_WRONG)
+			return false
+		}
+		clientType := b.getClientPlatform(ctx, string(ctx.URI().QueryArgs().Peek("appkey")))
+		if build < b.getMinBuild(clientType) && !b.reportOnly(policyVersion, fmt.Sprintf("build %d of %s", build, clientType)) {
+			writeErrorJSON(ctx, ERROR_CODE_HEADER_MIN_VERSION, b.getUpgradeMessage(MSG_ERROR_HEADER_MIN_VERSION))
+			return false
+		}
+		for _, r := range b.config.VersionPolicy.BlockedBuilds {
+			if r.contains(build) && !b.reportOnly(policyVersion, fmt.Sprintf("blocked build %d of %s", build, clientType)) {
+				writeErrorJSON(ctx, ERROR_CODE_HEADER_BLOCKED_VERSION, b.getUpgradeMessage(MSG_ERROR_HEADER_BLOCKED_VERSION))
+				return false
+			}
+		}
+		return true
+	}
+
+	writeErrorJSON(ctx, ERROR_CODE_HEADER_WRONG, MSG_ERROR_HEADER_WRONG)
+	return false
+}
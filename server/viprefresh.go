@@ -0,0 +1,187 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/JasonKhew96/biliroaming-go-server/database"
+	"github.com/JasonKhew96/biliroaming-go-server/models"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultVipRefreshWindow       = 24 * time.Hour
+	defaultVipRefreshActiveWithin = time.Hour
+	defaultVipRefreshBatchSize    = 100
+	maxRecentCredentials          = 10000
+	// vipRefreshMinInterval skip users refreshed recently
+	vipRefreshMinInterval = time.Hour
+)
+
+// recentCredential raw credential of recently active user, memory only
+// since access keys are hashed in database
+type recentCredential struct {
+	uid        int64
+	accessKey  string
+	sessdata   string
+	clientType ClientType
+	userAgent  string
+	lastSeen   time.Time
+}
+
+// recentCredentials latest credential of each user
+type recentCredentials struct {
+	mu      sync.Mutex
+	entries map[int64]*recentCredential
+}
+
+func newRecentCredentials() *recentCredentials {
+	return &recentCredentials{
+		entries: make(map[int64]*recentCredential),
+	}
+}
+
+func (r *recentCredentials) touch(c *recentCredential) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.entries[c.uid]; !exists && len(r.entries) >= maxRecentCredentials {
+		var oldestUID int64
+		var oldest time.Time
+		for uid, v := range r.entries {
+			if oldest.IsZero() || v.lastSeen.Before(oldest) {
+				oldestUID = uid
+				oldest = v.lastSeen
+			}
+		}
+		delete(r.entries, oldestUID)
+	}
+	c.lastSeen = time.Now()
+	r.entries[c.uid] = c
+}
+
+func (r *recentCredentials) remove(uid int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, uid)
+}
+
+// active credentials seen within duration, drop the others
+func (r *recentCredentials) active(duration time.Duration) []*recentCredential {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	credentials := make([]*recentCredential, 0, len(r.entries))
+	for uid, v := range r.entries {
+		if time.Since(v.lastSeen) > duration {
+			delete(r.entries, uid)
+			continue
+		}
+		credentials = append(credentials, v)
+	}
+	return credentials
+}
+
+// touchCredential keep raw credential only if vip refresh is enabled
+func (b *BiliroamingGo) touchCredential(ctx *fasthttp.RequestCtx, uid int64, accessKey string, sessdata string, clientType ClientType) {
+	if !b.config.VipRefresh.Enabled {
+		return
+	}
+	b.recentCredentials.touch(&recentCredential{
+		uid:        uid,
+		accessKey:  accessKey,
+		sessdata:   sessdata,
+		clientType: clientType,
+		userAgent:  string(ctx.UserAgent()),
+	})
+}
+
+// needVipRefresh vip due date about to pass or just passed, or user cache about to expire
+func (b *BiliroamingGo) needVipRefresh(user *models.User, window time.Duration) bool {
+	now := time.Now()
+	// refresh before cached user expires, nothing to keep warm when user cache is disabled
+	if b.config.Cache.User > 0 && user.UpdatedAt.Before(now.Add(-b.config.Cache.User/2)) {
+		return true
+	}
+	if user.UpdatedAt.After(now.Add(-vipRefreshMinInterval)) {
+		return false
+	}
+	return user.VipDueDate.After(now.Add(-window)) && user.VipDueDate.Before(now.Add(window))
+}
+
+// runVipRefresh re-verify vip status of recently active users
+func (b *BiliroamingGo) runVipRefresh() (int64, error) {
+	c := b.config.VipRefresh
+	window := c.Window
+	if window <= 0 {
+		window = defaultVipRefreshWindow
+	}
+	activeWithin := c.ActiveWithin
+	if activeWithin <= 0 {
+		activeWithin = defaultVipRefreshActiveWithin
+	}
+	batchSize := c.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultVipRefreshBatchSize
+	}
+
+	var refreshed int64
+	for _, credential := range b.recentCredentials.active(activeWithin) {
+		if refreshed >= int64(batchSize) {
+			break
+		}
+		user, err := b.db.GetUser(credential.uid)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return refreshed, err
+		}
+		if err == nil && !b.needVipRefresh(user, window) {
+			continue
+		}
+		refreshed++
+		if err := b.refreshCredential(credential, user); err != nil {
+			b.sugar.Warnf("Refresh vip status of %d: %v", credential.uid, err)
+			b.metrics.inc("biliroaming_vip_refresh_total", "result", "failure")
+		}
+	}
+	return refreshed, nil
+}
+
+func (b *BiliroamingGo) refreshCredential(credential *recentCredential, user *models.User) error {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetUserAgent(credential.userAgent)
+
+	var account *accountInfo
+	var err error
+	if credential.sessdata != "" {
+		account, err = b.getCookieAccountInfo(ctx, credential.sessdata)
+	} else {
+		account, err = b.getAccountInfo(ctx, credential.accessKey, credential.clientType)
+	}
+	if err != nil {
+		b.recentCredentials.remove(credential.uid)
+		return err
+	}
+
+	if err := b.db.InsertOrUpdateUser(account.uid, account.name, account.vipDue); err != nil {
+		return err
+	}
+	if credential.sessdata != "" {
+		err = b.db.InsertOrUpdateCookieSession(credential.sessdata, account.uid)
+	} else {
+		err = b.db.InsertOrUpdateKey(credential.accessKey, account.uid, credential.clientType.String())
+	}
+	if err != nil {
+		return err
+	}
+
+	if user == nil || !user.VipDueDate.Equal(account.vipDue) {
+		b.sugar.Debugf("VIP status of %d changed", account.uid)
+		b.evictUser(account.uid, database.InvalidationUserReauth)
+		b.metrics.inc("biliroaming_vip_refresh_total", "result", "changed")
+	} else {
+		b.metrics.inc("biliroaming_vip_refresh_total", "result", "unchanged")
+	}
+	return nil
+}
@@ -6,7 +6,7 @@ CREATE TABLE users(
     updated_at TIMESTAMP NOT NULL
 );
 CREATE TABLE access_keys(
-    key CHAR(32) PRIMARY KEY NOT NULL,
+    key VARCHAR(64) PRIMARY KEY NOT NULL,
     uid BIGINT REFERENCES users(uid) NOT NULL,
     client_type VARCHAR(32) NOT NULL,
     created_at TIMESTAMP NOT NULL,
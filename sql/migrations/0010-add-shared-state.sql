@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limits(
+    key TEXT PRIMARY KEY NOT NULL,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE UNLOGGED TABLE IF NOT EXISTS auth_caches(
+    key VARCHAR(64) PRIMARY KEY NOT NULL,
+    uid BIGINT NOT NULL,
+    is_login BOOLEAN NOT NULL,
+    is_vip BOOLEAN NOT NULL,
+    is_blacklist BOOLEAN NOT NULL,
+    is_whitelist BOOLEAN NOT NULL,
+    ban_until TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS rate_limits;
+DROP TABLE IF EXISTS auth_caches;
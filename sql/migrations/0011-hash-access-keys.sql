@@ -0,0 +1,9 @@
+-- +migrate Up
+-- existing keys are hashed once at startup, see database.HashPlainKeysOnce
+ALTER TABLE access_keys
+ALTER COLUMN key TYPE VARCHAR(64);
+
+-- +migrate Down
+DELETE FROM access_keys WHERE LENGTH(key) > 32;
+ALTER TABLE access_keys
+ALTER COLUMN key TYPE CHAR(32);
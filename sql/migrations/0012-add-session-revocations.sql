@@ -0,0 +1,8 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS session_revocations(
+    uid BIGINT PRIMARY KEY NOT NULL,
+    revoked_before TIMESTAMP NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS session_revocations;
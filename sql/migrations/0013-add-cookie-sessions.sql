@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS cookie_sessions(
+    key VARCHAR(64) PRIMARY KEY NOT NULL,
+    uid BIGINT REFERENCES users(uid) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS cookie_sessions;
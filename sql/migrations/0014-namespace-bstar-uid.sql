@@ -0,0 +1,8 @@
+-- +migrate Up
+-- bstar 账号改为独立验证，uid 加上偏移量 (1 << 62) 与 bilibili uid 区分
+-- 旧版本经 bilibili 接口验证的 bstar access_key 需要重新验证
+DELETE FROM access_keys WHERE client_type = 'bstar_a';
+
+-- +migrate Down
+DELETE FROM access_keys WHERE uid >= 4611686018427387904;
+DELETE FROM users WHERE uid >= 4611686018427387904;
@@ -0,0 +1,8 @@
+-- +migrate Up
+CREATE UNLOGGED TABLE IF NOT EXISTS request_nonces(
+    key TEXT PRIMARY KEY NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS request_nonces;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- one-off tasks run at startup, see database.HashPlainKeysOnce
+CREATE TABLE IF NOT EXISTS maintenance_tasks(
+    name VARCHAR(64) PRIMARY KEY NOT NULL,
+    done_at TIMESTAMP NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS maintenance_tasks;